@@ -13,6 +13,7 @@ import (
 var (
 	applyFilename string
 	applyYes      bool
+	applyDryRun   bool
 )
 
 // applyCmd represents the apply command
@@ -29,7 +30,7 @@ directory [config.(yaml|jsonnet)].`,
 		if f == "" {
 			f = configFilenameFromDir(cfgDir)
 		}
-		if err := apply(f, !applyYes); err != nil {
+		if err := apply(f, !applyYes, applyDryRun); err != nil {
 			fatal(err)
 		}
 	},
@@ -41,9 +42,10 @@ func init() {
 	// Flags and configuration settings
 	applyCmd.PersistentFlags().StringVarP(&applyFilename, "filename", "f", "", "configuration file")
 	applyCmd.Flags().BoolVarP(&applyYes, "yes", "y", false, "don't ask for confirmation, just apply")
+	applyCmd.Flags().BoolVar(&applyDryRun, "dry-run", false, "print the changes that would be applied, without applying them")
 }
 
-func apply(path string, interactive bool) error {
+func apply(path string, interactive, dryRun bool) error {
 	parseRes, err := parseConfig(path, "")
 	if err != nil {
 		return err
@@ -64,6 +66,10 @@ func apply(path string, interactive bool) error {
 		return errors.New("cannot compare upstream with local filters")
 	}
 
+	if dryRun {
+		fmt.Print(diff.Plan())
+		return nil
+	}
 	if diff.Empty() {
 		fmt.Println("No changes have been made.")
 		return nil
@@ -67,7 +67,7 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _GmailctlLibsonnet = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x94\x4d\x6f\xe3\x36\x10\x86\xef\xfa\x15\x2f\xf6\x92\x18\xab\x5a\xdb\xab\x83\x14\x68\xb7\xed\xb1\xbd\xe4\x66\xf8\xc0\x50\x23\x6b\x00\x8a\x34\x86\xa3\x78\x85\x20\xff\xbd\xe0\x87\x64\x67\x81\x5e\x0c\x6a\xbe\x5e\xce\x33\x1c\x77\x1d\xce\x93\x61\x67\xd5\x21\xaa\xf1\xbd\x91\x1e\x8e\x5f\xc5\xc8\xd2\x74\x5d\xd3\x75\x78\x19\x39\xc2\x06\xaf\x86\x7d\xc4\xac\xec\x58\x99\x22\x74\x34\x8a\xc8\xd3\xc5\xf1\xb0\xc0\x86\x69\x0a\x1e\x03\x3b\x25\x41\xb8\x90\x18\xe5\xe0\xe3\xbe\x49\x35\xec\x68\xd8\xff\x9d\x7d\x11\x1c\x61\x30\xcc\xde\xa6\x80\x5c\xa6\xc5\x99\xdf\xc8\xc3\xc0\x71\x54\x84\x01\x32\x3b\x8a\x6d\x4a\x15\xd2\x59\x7c\x4a\xf1\x74\x2d\xfe\xeb\x48\x42\xd0\x91\x4a\x18\x8c\x50\x51\xa0\x1e\x1a\xce\x23\x29\x49\xce\xbd\x8e\x6c\x47\x4c\x64\x7c\xbd\xae\x8e\xb4\xc0\x1a\x8f\x57\x02\x7b\x25\xb9\x08\x29\xf5\x30\xa9\x7e\x2e\x91\xd2\xc2\x80\x2f\x3c\x80\x5c\x5c\x7f\xbf\xec\x0b\x09\x82\x50\x9c\x9d\xa6\x1e\x5e\x4d\x64\x6b\x9c\x5b\x7e\xbe\x76\xbd\x1f\x19\x3b\x56\x1e\x29\x99\x23\xa6\xd0\xf3\xc0\xd4\xe3\x75\x81\xe9\x7b\xf6\x67\x18\x8f\xdf\xff\xf9\x13\x57\xd6\x31\x37\xe4\xe9\x9c\xb9\xa5\x62\xc6\xb9\x64\x4b\xc9\x17\xa1\x37\x0e\x73\xac\xf5\xe2\xbe\x71\xc1\x1a\xf7\x89\xeb\xe3\x10\x77\x78\x6e\x80\xae\xab\x53\x5a\x4a\xd3\x2b\xdb\x74\x39\xb0\x6e\x44\x59\x63\xd1\xa3\xbe\xd6\xdd\x37\x40\x29\x5c\xec\x8f\xb2\xc3\x33\xde\xe1\x83\x1e\x20\xfb\x3a\xdc\x8f\xa7\x22\x22\x64\x67\x89\xfc\x46\x55\x26\xa4\x47\x21\x61\x3e\x8f\xf9\xea\xe4\x68\x22\xaf\x31\xb7\x22\xb2\x95\x36\xf3\x8f\x47\x23\xd2\x82\xdb\x55\xbe\x85\xcc\xde\xb3\x3f\x97\x06\x00\x1e\xc0\xf8\xed\x19\x51\xfb\xbd\x23\x7f\xd6\x31\xa5\xec\x12\x0e\x9f\x03\xb0\x66\xe4\x2f\x72\x91\xaa\xb9\xeb\x2a\xc7\x6b\xed\x37\xae\x8c\xc3\x70\xb8\xc5\xfc\xf2\x7f\xb4\x6f\xa8\xf3\x2c\x7f\xce\xb0\xb3\x08\x79\xcd\xce\xea\x5b\x81\x5d\x25\xb1\xaa\x46\x54\xa2\x87\x3b\x0b\x60\x7c\x7f\xd8\x90\x7f\xc5\xd1\x88\x1c\xf9\x54\xb9\x9e\xda\x2d\xf2\xe3\x76\x34\x79\x4b\xe2\x01\x35\xb6\x7e\xaf\x01\x79\x16\x39\x6e\xa3\x8a\xaf\xf8\xb5\xbd\x57\xa9\xb3\x2c\x05\x76\xa7\x0d\x76\xf1\x5d\xe5\xb4\x83\x1a\x76\x51\x85\xad\x3e\x35\x4d\xc6\x7f\x47\x3e\xbf\xab\x67\x7c\xcb\xf4\x71\x3c\x35\x05\x78\x96\x1c\x62\x9b\xd4\x56\x8d\x21\x1e\xbf\x65\x89\x63\x3e\x9d\x76\x4f\x79\xfd\x7b\x16\xb2\xea\x96\x97\x80\xc9\xa8\x1d\x29\x22\x78\xb7\x80\xd2\x5f\xcf\xfd\x3a\x93\xe5\x0b\x27\xbe\x1c\xc1\x3e\xdb\x1e\x5e\xfe\x7d\x48\x35\x06\x26\xd7\xb7\xe9\x29\x16\xf3\xf7\xef\x0f\x08\x82\x87\x3f\xf2\xc1\xd3\xb6\x13\x37\xb1\xc7\xad\xde\xae\x8e\x26\x0f\xe0\x98\x91\xbd\x43\xc3\xe1\x4e\xb1\x32\xaf\x8f\xfd\x1d\xd6\x7e\xf2\x16\xff\xa9\x6d\x3e\x4a\x4b\x7f\xfd\xb8\x04\xc9\x9b\x53\xff\xc8\x62\x93\x04\xee\x17\xf2\xf0\xe9\x2b\xa5\xdf\xae\x76\xb8\x3b\xb7\xcd\x47\xf3\x5f\x00\x00\x00\xff\xff\x5f\xa6\x5a\xa3\x88\x05\x00\x00")
+var _GmailctlLibsonnet = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x54\xc1\x8e\xe3\x36\x0c\xbd\xfb\x2b\x1e\xf6\x92\x04\xeb\xc6\xdb\xab\x07\x53\xa0\xdd\x76\x6f\x6d\x2f\x73\x0b\x72\x50\x64\x3a\x26\x20\x4b\x81\x44\x4f\x36\x18\xcc\xbf\x17\x94\x65\x27\xb3\x6d\x2f\x86\x4c\x91\xef\xe9\x3d\x8a\x6a\x1a\x9c\x47\xc3\xce\x8a\x43\x12\xe3\x3b\x13\x3b\x38\x3e\x45\x13\x6f\x55\xd3\x54\x4d\x83\x97\x81\x13\x6c\xf0\x62\xd8\x27\x4c\xc2\x8e\x85\x29\x41\x06\x23\x48\x3c\x5e\x1c\xf7\x37\xd8\x30\x8e\xc1\xa3\x67\x27\x14\x11\x2e\x14\x8d\x70\xf0\x69\x5f\x29\x86\x1d\x0c\xfb\x6f\x79\x2f\x81\x13\x0c\xfa\xc9\x5b\x4d\xc8\x30\x35\xce\xfc\x4a\x1e\x06\x8e\x93\x20\xf4\x88\x93\xa3\x54\x6b\x69\x24\x99\xa2\xd7\x12\x4f\xd7\x79\xff\x3a\x50\x24\xc8\x40\x73\x1a\x4c\xa4\x99\x81\x3a\x48\x38\x0f\x24\x14\x73\xed\x75\x60\x3b\x60\x24\xe3\xcb\x71\x65\xa0\x1b\xac\xf1\x38\x11\xd8\x0b\xc5\x4b\x24\xa1\x0e\x46\xf1\x33\x84\x96\x85\x1e\x9f\xb8\x07\xb9\xb4\x7c\x3f\xed\x35\xfe\xa2\x8c\x94\x26\x27\xaa\xe1\x64\x12\x5b\xe3\xdc\xed\xc7\x63\x97\xf3\x91\xb1\x43\xf1\x43\x8b\x39\x61\x0c\x1d\xf7\x4c\x1d\x4e\x37\x98\xae\x63\x7f\x86\xf1\xf8\xf5\xaf\xdf\x71\x65\x19\xb2\x20\x4f\xe7\xec\x9b\x7a\x60\x9c\xd3\x98\x16\x5f\x22\xbd\x72\x98\x52\xc1\x4b\xfb\xca\x05\x6b\xdc\x07\x5f\xb7\x7d\xda\xe1\xb9\x02\x9a\xa6\x74\xe9\x36\x8b\x5e\xbc\x55\xb3\xc0\xb2\x3a\xca\x92\x66\x3e\xea\x0a\xee\xbe\x02\x66\xe0\x39\xbe\x8d\x3b\x3c\xe3\x0d\x3e\x48\x8b\xb8\x2f\xcd\x7d\x7f\x9a\x49\x22\xd9\x29\x26\x7e\xa5\x42\x13\xf4\x52\xc4\x30\x9d\x87\x7c\x74\x72\x34\x92\x97\x94\xa5\xc4\xb8\x42\x9b\xe9\xfb\xd6\xc4\x58\x83\xeb\x85\xbe\x46\x9c\xbc\x67\x7f\x9e\x05\x00\xdc\x83\xf1\xcb\x33\x92\x74\x7b\x47\xfe\x2c\x83\x96\xec\xd4\x0e\x9f\x13\xb0\x54\xe4\x3f\x72\x89\x4a\xb8\x69\x8a\x8f\xd7\xa2\x37\x2d\x1e\x87\xbe\xbd\xe7\xfc\xf4\x7f\x6e\xdf\xad\xd6\xf2\xf4\x63\x85\x9d\x62\x24\x2f\x19\xbb\xec\x2d\x86\x5d\xa3\x7a\x55\x82\x28\x8e\xb6\x0f\x11\xc0\xf8\xae\x5d\x34\xe3\x33\x0e\x26\xc6\x03\x1f\x8b\xaf\xc7\x7a\xcd\x7c\xbf\x2f\x4d\x9e\x92\xd4\xa2\xe4\x96\xff\x25\x21\xf7\x42\x17\x77\x57\xf1\x19\x3f\xaf\xce\x2a\x4b\xe9\xe5\x0c\xb0\x3b\xae\x66\xcf\x7b\xd7\x78\xdc\x41\x0c\xbb\x24\x91\xad\x3c\x55\x55\xb6\xff\xc1\xf9\x7c\xaf\x9e\xf1\x45\xed\xf1\x38\x1c\x2b\xe8\x50\x50\xa6\xec\x53\xad\x6c\x0b\x47\x9f\x0e\x5f\x32\xc5\x21\xaf\x8e\xbb\xa7\x3c\xfe\x1d\x47\xb2\xe2\x6e\x2f\x01\xa3\x11\x3b\x50\x42\xf0\xee\x06\xd2\xa7\xe7\x71\x9c\xc9\xf2\x85\xd5\x5f\x4e\x60\x9f\x63\x9b\x97\xbf\x37\x8a\xd1\x33\xb9\xae\xd6\xab\x38\x87\xbf\x7e\xdd\x20\x44\x6c\x7e\xcb\x0b\x4f\xeb\x4c\xdc\xc9\xb6\x2b\xde\xae\xb4\x26\x37\xe0\x90\x2d\x7b\x83\x84\xf6\x81\xb1\x78\x5e\x2e\xfb\x1b\xac\xfd\xb0\x3b\xef\x1f\xeb\xea\x7d\x96\xe4\x83\x7c\x8b\x61\xfc\x93\x96\xa9\xd6\x17\x86\xca\xac\xcd\x0d\xad\x91\xc2\x3c\x1a\x2c\xe8\x02\x25\xbf\x91\x59\xbf\x02\x64\xed\x09\x49\xd1\x4f\x3a\xa9\x84\x70\xf5\xfa\x76\xf6\xf9\xc7\x58\x1b\x26\x2f\x8b\xaa\x95\x6f\x3b\x83\xff\x87\xa2\xc2\xfa\x51\x46\x1f\xc3\xd8\x62\x33\xd2\xe6\xdf\x1a\xfe\xf8\x7e\x09\x51\x2f\xc9\xf2\x18\xa7\x4a\x21\x1f\x1f\x95\xf6\xc3\x9f\x96\xdf\xed\x6d\x1f\xd6\xba\xb3\x1e\xb1\xbd\x2f\xeb\xea\xbd\xfa\x67\x00\x94\x59\x40\xe9\x64\x06\x00\x00")
 
 func GmailctlLibsonnetBytes() ([]byte, error) {
 	return bindataRead(
@@ -87,7 +87,7 @@ func GmailctlLibsonnet() (*asset, error) {
 	return a, nil
 }
 
-var _DefaultConfigJsonnet = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x91\x41\x6b\xe3\x48\x10\x85\xef\xfa\x15\x6f\xd9\x83\x2e\xc6\x62\xf7\xa8\x10\x48\x20\x86\x0d\xac\xe3\x90\x71\x0e\xc3\x90\x43\x49\x2e\x59\xcd\xb4\xba\x44\x75\xc9\x13\x13\xfc\xdf\x87\x56\x3b\xc9\x24\xe4\xd4\xae\xee\xe7\xef\xd5\x7b\xaa\x2a\xdc\x6d\xb6\xab\x1a\xdb\xde\x45\xb8\x08\x42\x74\xc3\xe8\x19\xfc\x4c\xe9\x5c\x16\x55\x85\x7b\xcf\x14\x19\xca\x1d\x2b\x4c\xd0\x9b\x8d\xb1\xae\xaa\xbd\xb3\x7e\x6a\x96\xad\x0c\xd5\xd0\xa8\x55\xfb\x81\x9c\x6f\xcd\xff\xdd\x4a\xe8\xdc\x7e\x52\x32\x27\x01\x9d\x28\x76\xd2\x46\x50\x23\x93\x25\xa0\xf5\x8c\xac\x49\x8f\x03\xd9\x12\x37\x12\x4a\x4b\xd3\x9e\x2d\x79\xb4\x3d\x85\x3d\xff\xa1\x7c\xa5\x35\xdc\x89\x72\x92\xd0\x38\xfa\x23\x5c\x26\x0a\x8e\x32\x29\xe4\x57\x80\x0b\x8d\x3c\xff\x55\xa4\xeb\xdb\x61\x14\xb5\x99\x12\x8d\xc2\x8e\x74\x07\xef\x1a\x25\x3d\x16\x5e\x5a\xf2\x69\xc2\x25\x5c\xd6\x95\xaf\x09\x96\xde\x35\x51\x42\x60\x2b\x2f\x66\xd0\x37\x19\x18\x53\xe4\x6e\xf2\x38\x90\x3a\x6a\x3c\x47\x48\x80\xc9\x98\xde\xb7\x9b\x9b\x4d\x8d\xfb\xc9\xf2\x1a\x9c\x38\xe8\x59\xf9\x6c\x33\x30\x2e\x51\x7e\xdf\x3c\x3e\x2c\x57\xeb\xeb\xdb\xff\xaf\x66\xa7\x54\x5d\x79\x71\x96\x98\xac\x93\xe8\x05\x26\x75\xd2\x9f\x2e\x8a\xd9\x7a\xdb\x33\xa8\xb5\x89\xfc\xc7\x26\x8a\x97\x02\xa8\x2a\xac\x53\x2e\x13\x3d\xa2\x67\xda\xb1\x16\xc0\x81\x35\x3a\x09\x35\xca\xc3\x3f\xe4\xc7\x9e\xfe\x2d\x17\x05\x40\x93\xf5\xa2\x35\xd2\x1f\x81\x40\x03\xd7\x79\x27\xdc\x5d\xaf\x57\xf8\x6f\xf5\xb0\x9a\x75\xc8\xfb\xa7\x2d\xd2\x78\x5a\x14\xd9\x29\x87\x7c\x8c\xfc\xde\xb5\x4e\xa9\x87\x39\x28\xf2\x50\xe3\xc7\x8c\xc8\x26\x40\xe7\xbc\xb1\xd6\x73\xbc\xc5\xf9\x8e\xda\x14\x20\xd6\x6f\x22\x60\x20\xfd\x99\xbf\x16\x05\xab\x61\x3a\xbd\xa9\x4f\xf9\xc7\xf9\xf8\xcc\x7d\x47\x74\x2a\x43\x8d\xb2\x21\xbd\x3a\x52\x2f\x32\xb7\xfb\x09\xf2\xa5\x37\x69\xdb\xbb\x03\x7f\x74\x05\x3c\x35\xec\x53\x9e\xb2\x13\x29\x9f\xbe\x5a\xe7\x69\x51\x9c\x8a\xdf\x01\x00\x00\xff\xff\x96\xa0\x39\x3d\x45\x03\x00\x00")
+var _DefaultConfigJsonnet = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x91\xcf\x6b\xdb\x4e\x10\xc5\xef\xfb\x57\xbc\x2f\xdf\x83\x2e\xc6\xa2\x3d\x2a\x04\x12\x88\xa1\x81\x3a\x0e\xa9\x73\x28\x21\x87\x91\x3c\xb2\x96\xae\x76\xc4\xec\xc8\x8d\x09\xfe\xdf\xcb\x4a\x49\xf3\x83\xe2\xc3\x32\xbb\xcf\x9f\x37\xef\xa9\x2c\x71\xb3\xd9\xae\x2a\x6c\x3b\x9f\xe0\x13\x08\xc9\xf7\x43\x60\xf0\x13\xe5\x73\xe9\xca\x12\xb7\x81\x29\x31\x94\x5b\x56\x98\xa0\x33\x1b\x52\x55\x96\x7b\x6f\xdd\x58\x2f\x1b\xe9\xcb\xbe\x56\x2b\xf7\x3d\xf9\xd0\x58\xf8\xbf\x91\xd8\xfa\xfd\xa8\x64\x5e\x22\x5a\x51\xec\xa4\x49\xa0\x5a\x46\xcb\x40\xeb\x18\xb3\x26\x3f\xf6\x64\x4b\x5c\x49\x2c\x2c\x4f\x7b\xb6\xec\xd1\x74\x14\xf7\xfc\x4e\xf9\x4a\xab\xb9\x15\xe5\x2c\xa1\x61\x08\x47\xf8\x99\x28\x38\xca\xa8\x90\xdf\x11\x3e\xd6\xf2\xf4\x9f\xcb\xd7\xd7\xfd\x20\x6a\x13\x25\x19\xc5\x1d\xe9\x0e\xc1\xd7\x4a\x7a\x74\x41\x1a\x0a\x79\xc2\x39\xfc\xac\x2b\x5e\x13\x2c\x83\xaf\x93\xc4\xc8\x56\x9c\x4d\xa0\x1f\xd2\x33\xc6\xc4\xed\x18\x70\x20\xf5\x54\x07\x4e\x90\x08\x93\x21\xbf\x6f\x37\x57\x9b\x0a\xb7\xa3\xcd\x6b\x70\xe6\xa0\x63\xe5\x17\x9b\x9e\x71\x8e\xe2\xe7\xe6\xfe\x6e\xb9\x5a\x5f\x5e\x7f\xbf\x98\x9c\x72\x75\xc5\xd9\x8b\xc4\x64\x9d\x45\xcf\x30\xa9\xd0\x33\x4e\x67\x6e\xb2\xde\x76\x0c\x6a\x6c\xa4\xf0\xb1\x09\xf7\xec\x80\xb2\xc4\x3a\xe7\x32\xd1\x23\x3a\xa6\x1d\xab\x03\x0e\xac\xc9\x4b\xac\x50\x1c\xbe\x50\x18\x3a\xfa\x5a\x2c\x1c\x40\xa3\x75\xa2\x15\xf2\x1f\x81\x48\x3d\x57\xf3\x4e\xb8\xb9\x5c\xaf\xf0\x6d\x75\xb7\x9a\x74\x98\xf7\xcf\x5b\xe4\xf1\xb4\x70\xb3\xd3\x1c\xf2\x3e\xf1\x5b\xd7\x3a\xe6\x1e\xa6\xa0\x98\x87\x0a\x0f\x13\x62\x36\x01\x5a\x1f\x8c\xb5\x9a\xe2\x2d\x5e\xee\xa8\xc9\x01\xd2\xeb\x26\xf9\xd7\x93\xfe\x9a\xbf\x16\x45\xab\x60\x3a\xfe\x55\x9f\x16\xee\xdd\xf1\x99\xfb\x86\x68\x55\xfa\x0a\x45\x4d\x7a\x71\xa4\x4e\x64\x6a\xf7\x13\xe4\x9f\xde\xa4\x4d\xe7\x0f\xfc\xd1\x15\x08\x54\x73\x48\x15\x1e\x8a\x56\xa4\x78\xfc\x44\x3a\x2d\x1c\xf0\xb8\x70\x27\xf7\x67\x00\x96\xa0\x39\x3d\x45\x03\x00\x00")
 
 func DefaultConfigJsonnetBytes() ([]byte, error) {
 	return bindataRead(
@@ -1,9 +1,13 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"path"
+	"strings"
+	"time"
 
+	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 
 	"github.com/mbrt/gmailctl/pkg/config"
@@ -14,8 +18,9 @@ import (
 
 type parseResult struct {
 	config  cfgv2.Config
-	rules   []parser.Rule
+	rules   []parser.Rule // One per config rule, empty if disabled.
 	filters filter.Filters
+	opts    filter.GenerateOptions
 }
 
 func configFilenameFromDir(cfgDir string) string {
@@ -44,14 +49,85 @@ func parseConfig(path, originalPath string) (parseResult, error) {
 			config.LatestVersion)
 	}
 
-	res.rules, err = parser.Parse(res.config)
+	// Disabled rules are kept in the config and skipped by the parser, so
+	// that the errors and warnings refer to the original rule indexes.
+	if disabled := countDisabled(res.config); disabled > 0 {
+		stderrPrintf("WARNING: %d disabled rules are skipped.\n", disabled)
+	}
+	var expired []string
+	if res.config, expired = cfgv2.PruneExpired(res.config, time.Now()); len(expired) > 0 {
+		stderrPrintf("WARNING: expired rules are skipped: %s.\n", strings.Join(expired, ", "))
+	}
+	if account != "" {
+		res.config = res.config.ForAccount(account)
+	}
+	if res.config.FirstMatch {
+		res.config, err = cfgv2.ApplyFirstMatchSemantics(res.config)
+		if err != nil {
+			return res, errors.Wrap(err, "invalid config file")
+		}
+	}
+
+	// The rules are parsed once, and shared by validation, duplicates
+	// detection and generation.
+	res.rules, err = parser.ParseRules(res.config)
+	// Report all the problems at once, instead of only the first one.
+	if verr := filter.ValidateRules(res.rules); verr != nil {
+		err = multierror.Append(err, verr)
+	}
 	if err != nil {
-		return res, errors.Wrap(err, "cannot parse config file")
+		return res, errors.Wrap(err, "invalid config file")
+	}
+
+	for _, group := range filter.FindDuplicates(res.rules) {
+		stderrPrintf("WARNING: rules %s expand to identical filters.\n", formatRuleIndexes(group))
 	}
 
-	res.filters, err = filter.FromRules(res.rules)
+	res.opts = genOpts
+	if res.opts.OrStyle, err = parseOrStyle(orStyle); err != nil {
+		return res, err
+	}
+	var warnings []filter.Warning
+	res.filters, warnings, err = filter.FromParsedRules(res.rules, res.opts)
 	if err != nil {
 		return res, errors.Wrap(err, "error exporting to filters")
 	}
+	for _, w := range warnings {
+		stderrPrintf("WARNING: %s.\n", w)
+	}
 	return res, nil
 }
+
+func parseOrStyle(s string) (filter.OrStyle, error) {
+	switch s {
+	case "", "braces":
+		return filter.OrBraces, nil
+	case "keyword":
+		return filter.OrKeyword, nil
+	default:
+		return filter.OrBraces, errors.Errorf("unsupported OR style '%s' (possible values: braces, keyword)", s)
+	}
+}
+
+func countDisabled(config cfgv2.Config) int {
+	var res int
+	for _, r := range config.Rules {
+		if !r.IsEnabled() {
+			res++
+		}
+	}
+	return res
+}
+
+// formatRuleIndexes returns a list of rule indexes in a readable form,
+// e.g. '#1, #3 and #4'.
+func formatRuleIndexes(idxs []int) string {
+	names := make([]string, len(idxs))
+	for i, idx := range idxs {
+		names[i] = fmt.Sprintf("#%d", idx)
+	}
+	if len(names) < 2 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
@@ -13,6 +13,7 @@ import (
 
 var (
 	debugFilename string
+	debugSummary  bool
 )
 
 // debugCmd represents the debug command
@@ -24,7 +25,11 @@ with handy URLs to Gmail search that can be used to test that the
 filter applies to the intended emails.
 
 By default debug uses the configuration file inside the config
-directory [config.(yaml|jsonnet)].`,
+directory [config.(yaml|jsonnet)].
+
+With '--summary' a report of the generated filters (e.g. the number of
+filters and labels, and how many times every action is used) is printed
+at the end.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		f := debugFilename
 		if f == "" {
@@ -41,6 +46,7 @@ func init() {
 
 	// Flags and configuration settings
 	debugCmd.PersistentFlags().StringVarP(&debugFilename, "filename", "f", "", "configuration file")
+	debugCmd.PersistentFlags().BoolVar(&debugSummary, "summary", false, "print a summary of the generated filters")
 }
 
 func debug(path string) error {
@@ -58,7 +64,11 @@ func debug(path string) error {
 
 	for i := 0; i < len(parseRes.rules); i++ {
 		parsed := parseRes.rules[i]
-		criteria, err := filter.GenerateCriteria(parsed.Criteria)
+		if parsed.Criteria == nil {
+			// Disabled rule.
+			continue
+		}
+		criteria, err := filter.GenerateCriteriaWithOptions(parsed.Criteria, parseRes.opts)
 		if err != nil {
 			return errors.Wrap(err, "error generating criteria")
 		}
@@ -74,6 +84,9 @@ func debug(path string) error {
 		fmt.Println(string(b))
 	}
 
+	if debugSummary {
+		fmt.Printf("# Summary\n%s", filter.Summary(parseRes.filters))
+	}
 	return nil
 }
 
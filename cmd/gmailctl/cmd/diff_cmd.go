@@ -11,6 +11,8 @@ import (
 
 var (
 	diffFilename string
+	diffSummary  bool
+	diffColor    bool
 )
 
 // diffCmd represents the diff command
@@ -38,6 +40,8 @@ func init() {
 
 	// Flags and configuration settings
 	diffCmd.PersistentFlags().StringVarP(&diffFilename, "filename", "f", "", "configuration file")
+	diffCmd.Flags().BoolVarP(&diffSummary, "summary", "s", false, "print the added and removed filters instead of a unified diff")
+	diffCmd.Flags().BoolVar(&diffColor, "color", false, "color the summary")
 }
 
 func diff(path string) error {
@@ -61,6 +65,10 @@ func diff(path string) error {
 		return errors.New("cannot compare upstream with local filters")
 	}
 
+	if diffSummary {
+		fmt.Print(filter.FormatDiff(diff.Added, diff.Removed, diffColor))
+		return nil
+	}
 	fmt.Print(diff)
 	return nil
 }
@@ -7,12 +7,15 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/mbrt/gmailctl/pkg/export/json"
+	"github.com/mbrt/gmailctl/pkg/export/sieve"
 	"github.com/mbrt/gmailctl/pkg/export/xml"
 )
 
 var (
 	exportFilename string
 	exportOutput   string
+	exportFormat   string
 )
 
 // exportCmd represents the export command
@@ -23,6 +26,14 @@ var exportCmd = &cobra.Command{
 This allows to import them from within the Gmail settings or to share
 them with other people.
 
+With '--format json' the filters are exported in a simple JSON format
+instead, useful for other tools, while '--format sieve' produces a Sieve
+script for other mail providers. Not all the Gmail features can be
+converted to Sieve.
+
+Gmail doesn't deduplicate imported filters: use '--dedupe' to remove the
+identical filters generated by different rules.
+
 By default export uses the configuration file inside the config
 directory [config.(yaml|jsonnet)].`,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -30,6 +41,9 @@ directory [config.(yaml|jsonnet)].`,
 		if f == "" {
 			f = configFilenameFromDir(cfgDir)
 		}
+		if exportFormat != "xml" && exportFormat != "json" && exportFormat != "sieve" {
+			fatal(errors.Errorf("unsupported format '%s' (possible values: xml, json, sieve)", exportFormat))
+		}
 		if err := export(f, exportOutput); err != nil {
 			fatal(err)
 		}
@@ -42,6 +56,7 @@ func init() {
 	// Flags and configuration settings
 	exportCmd.PersistentFlags().StringVarP(&exportFilename, "filename", "f", "", "configuration file")
 	exportCmd.PersistentFlags().StringVarP(&exportOutput, "output", "o", "", "output file (defaut to stdout)")
+	exportCmd.PersistentFlags().StringVar(&exportFormat, "format", "xml", "output format (xml, json or sieve)")
 }
 
 func export(inputPath, outputPath string) (err error) {
@@ -70,5 +85,17 @@ func exportWithOut(path string, out io.Writer) error {
 	if err != nil {
 		return err
 	}
-	return xml.DefaultExporter().Export(pres.config.Author, pres.filters, out)
+	// All the formats export the same filters, generated with the same
+	// options.
+	switch exportFormat {
+	case "json":
+		return json.DefaultExporter().Export(pres.filters, out)
+	case "sieve":
+		return sieve.DefaultExporter().Export(pres.filters, out)
+	default:
+		for _, w := range xml.CheckUnsupported(pres.filters) {
+			stderrPrintf("WARNING: %s.\n", w)
+		}
+		return xml.DefaultExporter().Export(pres.config.Author, pres.filters, out)
+	}
 }
@@ -7,12 +7,19 @@ import (
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
+
+	"github.com/mbrt/gmailctl/pkg/filter"
 )
 
 var (
 	cfgDir          string
 	credentialsPath string
 	tokenPath       string
+	account         string
+	// genOpts are the options used to generate the filters, set through
+	// the global flags.
+	genOpts filter.GenerateOptions
+	orStyle string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -40,6 +47,13 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 	rootCmd.PersistentFlags().StringVar(&cfgDir, "config", "", "config directory (default is $HOME/.gmailctl)")
+	rootCmd.PersistentFlags().StringVar(&account, "account", "", "use only the rules applying to the given account (default is all the rules)")
+	rootCmd.PersistentFlags().BoolVar(&genOpts.SortOr, "sort-or", false, "sort the values grouped in an OR, so that their order doesn't change the filters")
+	rootCmd.PersistentFlags().BoolVar(&genOpts.SortFilters, "sort-filters", false, "sort the generated filters, so that the order of the rules doesn't change the output")
+	rootCmd.PersistentFlags().StringVar(&orStyle, "or-style", "braces", "syntax of the values grouped in an OR (braces or keyword)")
+	rootCmd.PersistentFlags().BoolVar(&genOpts.StripDestructive, "strip-destructive", false, "remove the destructive actions (e.g. delete or archive), to test new rules safely")
+	rootCmd.PersistentFlags().BoolVar(&genOpts.CheckAddresses, "check-addresses", false, "warn about addresses that look like typos (e.g. missing '@')")
+	rootCmd.PersistentFlags().BoolVar(&genOpts.Dedupe, "dedupe", false, "remove the identical filters generated by different rules")
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -40,8 +40,18 @@ local directlyTo(recipient) = {
   ],
 };
 
+// notFromMe modifies the given filter, so that it doesn't match
+// emails sent by the owner of the account.
+local notFromMe(filter) = {
+  and: [
+    filter,
+    { not: { from: 'me' } },
+  ],
+};
+
 // Exported functions
 {
   chainFilters: chainFilters,
   directlyTo: directlyTo,
+  notFromMe: notFromMe,
 }
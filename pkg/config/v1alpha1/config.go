@@ -12,7 +12,12 @@ type Config struct {
 	Version string `yaml:"version"`
 	Author  Author `yaml:"author"`
 	Consts  Consts `yaml:"consts,omitempty"`
-	Rules   []Rule `yaml:"rules"`
+	// Defaults contains fallback values for constants missing from Consts.
+	Defaults Consts `yaml:"defaults,omitempty"`
+	// InterpolateEnv enables the substitution of '${VAR}' placeholders in
+	// the const values with the corresponding environment variables.
+	InterpolateEnv bool   `yaml:"interpolateEnv,omitempty"`
+	Rules          []Rule `yaml:"rules"`
 }
 
 // Consts maps names to a list of string values
@@ -25,8 +30,12 @@ type Author struct {
 }
 
 // ConstValue is a container for an array of string values.
+//
+// Consts can also include the values of other constants, by referring to
+// them by name in the Consts field.
 type ConstValue struct {
 	Values []string `yaml:"values"`
+	Consts []string `yaml:"consts,omitempty"`
 }
 
 // Rule is a filter with an associated action.
@@ -59,12 +68,18 @@ type CompositeFilters struct {
 // Every type of filter (e.g. Subject) is an array or requirements. They will be OR-ed
 // together. If multiple types of filters are specified, they will be put in AND together.
 type MatchFilters struct {
-	From    []string `yaml:"from,omitempty"`
-	To      []string `yaml:"to,omitempty"`
-	Cc      []string `yaml:"cc,omitempty"`
-	Subject []string `yaml:"subject,omitempty"`
-	Has     []string `yaml:"has,omitempty"`
-	List    []string `yaml:"list,omitempty"`
+	From []string `yaml:"from,omitempty"`
+	// FromDomain matches the senders from any of the given domains. Values
+	// are in OR together with the From ones.
+	FromDomain []string `yaml:"fromDomain,omitempty"`
+	To         []string `yaml:"to,omitempty"`
+	Cc         []string `yaml:"cc,omitempty"`
+	Bcc        []string `yaml:"bcc,omitempty"`
+	Subject    []string `yaml:"subject,omitempty"`
+	Has        []string `yaml:"has,omitempty"`
+	// HasAll requires all the given values, differently from Has.
+	HasAll []string `yaml:"hasAll,omitempty"`
+	List   []string `yaml:"list,omitempty"`
 }
 
 // Actions contains the actions to be applied to a set of emails.
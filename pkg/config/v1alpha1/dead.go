@@ -0,0 +1,154 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RuleWarning is a problem found in a rule that doesn't prevent the
+// config from being used.
+type RuleWarning struct {
+	// Index is the position of the rule in the config.
+	Index   int
+	Message string
+}
+
+func (w RuleWarning) String() string {
+	return fmt.Sprintf("rule #%d: %s", w.Index, w.Message)
+}
+
+// DetectDeadRules returns warnings for the rules that can never match.
+//
+// A rule is reported when its negated filters exclude all the emails
+// matched by a field, or when it has no filters at all after resolving the
+// constants. Rules with unresolvable constants are skipped,
+// since they are already reported by ResolveConsts.
+func DetectDeadRules(c Config) []RuleWarning {
+	consts := withDefaults(c.Consts, c.Defaults)
+	var res []RuleWarning
+
+	for i, r := range c.Rules {
+		f, err := resolveFilters(r.Filters, consts)
+		if err != nil {
+			continue
+		}
+		if r.Filters.Query == "" && emptyMatchFilters(f.MatchFilters) && emptyMatchFilters(f.Not) {
+			res = append(res, RuleWarning{
+				Index:   i,
+				Message: "no filters specified",
+			})
+			continue
+		}
+		if name, ok := contradiction(f.MatchFilters, f.Not); ok {
+			res = append(res, RuleWarning{
+				Index:   i,
+				Message: fmt.Sprintf("'%s' is both required and excluded by 'not'", name),
+			})
+		}
+	}
+
+	return res
+}
+
+type namedField struct {
+	name   string
+	values []string
+}
+
+func namedFields(mf MatchFilters) []namedField {
+	return []namedField{
+		{"from", mf.From},
+		{"fromDomain", mf.FromDomain},
+		{"to", mf.To},
+		{"cc", mf.Cc},
+		{"bcc", mf.Bcc},
+		{"subject", mf.Subject},
+		{"has", mf.Has},
+		{"hasAll", mf.HasAll},
+		{"list", mf.List},
+	}
+}
+
+// operand is a field of the filters, as interpreted by Gmail: its values are
+// in OR together, or in AND if all is set.
+type operand struct {
+	name   string
+	values []string
+	all    bool
+}
+
+func operands(mf MatchFilters) []operand {
+	// Domains are matched as senders, together with the from values.
+	from := append(append([]string{}, mf.From...), mf.FromDomain...)
+	return []operand{
+		{"from", from, false},
+		{"to", mf.To, false},
+		{"cc", mf.Cc, false},
+		{"bcc", mf.Bcc, false},
+		{"subject", mf.Subject, false},
+		{"has", mf.Has, false},
+		{"hasAll", mf.HasAll, true},
+		{"list", mf.List, false},
+	}
+}
+
+// contradiction returns the name of the field whose matches are all excluded
+// by the negated filters, if any.
+//
+// The negated fields are in AND together, so only a negation with a single
+// field can exclude all the matches of a field on its own.
+func contradiction(match, not MatchFilters) (string, bool) {
+	var negated []operand
+	for _, o := range operands(not) {
+		if len(o.values) > 0 {
+			negated = append(negated, o)
+		}
+	}
+	if len(negated) != 1 {
+		return "", false
+	}
+	n := negated[0]
+
+	for _, m := range operands(match) {
+		if m.name != n.name || len(m.values) == 0 {
+			continue
+		}
+		if n.all {
+			// Every email matched has all the excluded values
+			return m.name, containsAllFold(m.values, n.values)
+		}
+		// Every alternative matched is excluded
+		return m.name, containsAllFold(n.values, m.values)
+	}
+	return "", false
+}
+
+// containsAllFold returns true if all the values of b are contained in a.
+func containsAllFold(a, b []string) bool {
+	for _, v := range b {
+		if !containsFold(a, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func emptyMatchFilters(mf MatchFilters) bool {
+	for _, f := range namedFields(mf) {
+		for _, v := range f.values {
+			if strings.TrimSpace(v) != "" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func containsFold(a []string, s string) bool {
+	for _, v := range a {
+		if strings.EqualFold(strings.TrimSpace(v), strings.TrimSpace(s)) {
+			return true
+		}
+	}
+	return false
+}
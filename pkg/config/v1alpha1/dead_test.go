@@ -0,0 +1,133 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectDeadRules(t *testing.T) {
+	cfg := Config{
+		Consts: Consts{
+			"friends": {Values: []string{"a@gmail.com", "b@gmail.com"}},
+			"empty":   {},
+		},
+		Rules: []Rule{
+			{
+				// Fine
+				Filters: Filters{
+					CompositeFilters: CompositeFilters{
+						MatchFilters: MatchFilters{From: []string{"a@gmail.com"}},
+						Not:          MatchFilters{To: []string{"a@gmail.com"}},
+					},
+				},
+				Actions: Actions{Archive: true},
+			},
+			{
+				// Contradiction through a const
+				Filters: Filters{
+					CompositeFilters: CompositeFilters{
+						Not: MatchFilters{From: []string{"B@gmail.com", "a@gmail.com"}},
+					},
+					Consts: CompositeFilters{
+						MatchFilters: MatchFilters{From: []string{"friends"}},
+					},
+				},
+				Actions: Actions{Archive: true},
+			},
+			{
+				// Empty after resolution
+				Filters: Filters{
+					Consts: CompositeFilters{
+						MatchFilters: MatchFilters{Subject: []string{"empty"}},
+					},
+				},
+				Actions: Actions{Archive: true},
+			},
+		},
+	}
+
+	expected := []RuleWarning{
+		{Index: 1, Message: "'from' is both required and excluded by 'not'"},
+		{Index: 2, Message: "no filters specified"},
+	}
+	assert.Equal(t, expected, DetectDeadRules(cfg))
+	assert.Equal(t, "rule #1: 'from' is both required and excluded by 'not'", expected[0].String())
+}
+
+func TestDetectDeadRulesPartialExclusion(t *testing.T) {
+	cfg := Config{
+		Rules: []Rule{
+			{
+				// Still matches 'news'
+				Filters: Filters{
+					CompositeFilters: CompositeFilters{
+						MatchFilters: MatchFilters{Subject: []string{"news", "deals"}},
+						Not:          MatchFilters{Subject: []string{"deals"}},
+					},
+				},
+			},
+			{
+				// The negation excludes only the emails matching both
+				// fields
+				Filters: Filters{
+					CompositeFilters: CompositeFilters{
+						MatchFilters: MatchFilters{Subject: []string{"deals"}},
+						Not: MatchFilters{
+							Subject: []string{"deals"},
+							From:    []string{"shop@x.com"},
+						},
+					},
+				},
+			},
+			{
+				// Not all the required values are excluded
+				Filters: Filters{
+					CompositeFilters: CompositeFilters{
+						MatchFilters: MatchFilters{HasAll: []string{"a"}},
+						Not:          MatchFilters{HasAll: []string{"a", "b"}},
+					},
+				},
+			},
+		},
+	}
+	assert.Empty(t, DetectDeadRules(cfg))
+}
+
+func TestDetectDeadRulesAllExcluded(t *testing.T) {
+	cfg := Config{
+		Rules: []Rule{
+			{
+				Filters: Filters{
+					CompositeFilters: CompositeFilters{
+						MatchFilters: MatchFilters{Subject: []string{"news", "deals"}},
+						Not:          MatchFilters{Subject: []string{"Deals", "news", "spam"}},
+					},
+				},
+			},
+			{
+				// Domains are senders as well
+				Filters: Filters{
+					CompositeFilters: CompositeFilters{
+						MatchFilters: MatchFilters{FromDomain: []string{"x.com"}},
+						Not:          MatchFilters{From: []string{"x.com"}},
+					},
+				},
+			},
+			{
+				Filters: Filters{
+					CompositeFilters: CompositeFilters{
+						MatchFilters: MatchFilters{HasAll: []string{"a", "b", "c"}},
+						Not:          MatchFilters{HasAll: []string{"a", "b"}},
+					},
+				},
+			},
+		},
+	}
+	expected := []RuleWarning{
+		{Index: 0, Message: "'subject' is both required and excluded by 'not'"},
+		{Index: 1, Message: "'from' is both required and excluded by 'not'"},
+		{Index: 2, Message: "'hasAll' is both required and excluded by 'not'"},
+	}
+	assert.Equal(t, expected, DetectDeadRules(cfg))
+}
@@ -0,0 +1,87 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DescribeRule returns a one line human readable summary of the given rule,
+// after resolving its constants.
+//
+// The summary lists the criteria put in AND together, followed by the
+// actions, e.g.:
+//
+//	matches from {a@x.com, b@y.com} AND subject "invoice" -> label Finance, archive
+func DescribeRule(rule Rule, consts Consts) (string, error) {
+	f, err := resolveFilters(rule.Filters, consts)
+	if err != nil {
+		return "", err
+	}
+
+	var criteria []string
+	for _, nf := range namedFields(f.MatchFilters) {
+		if len(nf.values) > 0 {
+			criteria = append(criteria, describeField(nf))
+		}
+	}
+	for _, nf := range namedFields(f.Not) {
+		if len(nf.values) > 0 {
+			criteria = append(criteria, "NOT "+describeField(nf))
+		}
+	}
+	if rule.Filters.Query != "" {
+		criteria = append(criteria, fmt.Sprintf("query %q", rule.Filters.Query))
+	}
+
+	matches := "matches all emails"
+	if len(criteria) > 0 {
+		matches = "matches " + strings.Join(criteria, " AND ")
+	}
+
+	actions := describeActions(rule.Actions)
+	if len(actions) == 0 {
+		actions = []string{"no actions"}
+	}
+
+	return fmt.Sprintf("%s -> %s", matches, strings.Join(actions, ", ")), nil
+}
+
+func describeField(nf namedField) string {
+	values := make([]string, len(nf.values))
+	for i, v := range nf.values {
+		// Free text fields are quoted, to distinguish them from addresses.
+		if nf.name == "subject" || nf.name == "has" || nf.name == "hasAll" {
+			v = fmt.Sprintf("%q", v)
+		}
+		values[i] = v
+	}
+	if len(values) == 1 {
+		return fmt.Sprintf("%s %s", nf.name, values[0])
+	}
+	return fmt.Sprintf("%s {%s}", nf.name, strings.Join(values, ", "))
+}
+
+// describeActions lists the actions in the order they are usually thought
+// of: where the email goes first, then what happens to it.
+func describeActions(a Actions) []string {
+	var res []string
+	for _, l := range a.Labels {
+		res = append(res, "label "+l)
+	}
+	if a.Category != "" {
+		res = append(res, fmt.Sprintf("category %s", a.Category))
+	}
+	if a.Archive {
+		res = append(res, "archive")
+	}
+	if a.MarkRead {
+		res = append(res, "mark as read")
+	}
+	if a.MarkImportant {
+		res = append(res, "mark as important")
+	}
+	if a.Delete {
+		res = append(res, "delete")
+	}
+	return res
+}
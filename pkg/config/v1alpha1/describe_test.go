@@ -0,0 +1,59 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribeRule(t *testing.T) {
+	consts := Consts{
+		"billing": {Values: []string{"a@x.com", "b@y.com"}},
+	}
+	rule := Rule{
+		Filters: Filters{
+			CompositeFilters: CompositeFilters{
+				MatchFilters: MatchFilters{
+					Subject: []string{"invoice"},
+				},
+				Not: MatchFilters{
+					To: []string{"me+spam@x.com"},
+				},
+			},
+			Consts: CompositeFilters{
+				MatchFilters: MatchFilters{
+					From: []string{"billing"},
+				},
+			},
+		},
+		Actions: Actions{
+			MarkRead: true,
+			Archive:  true,
+			Labels:   []string{"Finance"},
+		},
+	}
+
+	got, err := DescribeRule(rule, consts)
+	assert.Nil(t, err)
+	assert.Equal(t, `matches from {a@x.com, b@y.com} AND subject "invoice" AND NOT to me+spam@x.com -> label Finance, archive, mark as read`, got)
+}
+
+func TestDescribeRuleEmpty(t *testing.T) {
+	got, err := DescribeRule(Rule{}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "matches all emails -> no actions", got)
+}
+
+func TestDescribeRuleMissingConst(t *testing.T) {
+	rule := Rule{
+		Filters: Filters{
+			Consts: CompositeFilters{
+				MatchFilters: MatchFilters{
+					From: []string{"missing"},
+				},
+			},
+		},
+	}
+	_, err := DescribeRule(rule, Consts{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,66 @@
+package v1alpha1
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// MergeConfigs merges multiple configs into a single one.
+//
+// Rules are concatenated in the given order, while consts and defaults are
+// merged together. Defining the same const in multiple configs is allowed
+// only if the definitions are identical. Version and author are taken from
+// the first config that specifies them, while environment interpolation is
+// enabled if any of the configs enables it.
+func MergeConfigs(cfgs ...Config) (Config, error) {
+	res := Config{}
+	for _, c := range cfgs {
+		if res.Version == "" {
+			res.Version = c.Version
+		}
+		if res.Author == (Author{}) {
+			res.Author = c.Author
+		}
+		consts, err := mergeConsts(res.Consts, c.Consts)
+		if err != nil {
+			return res, errors.Wrap(err, "error merging consts")
+		}
+		res.Consts = consts
+		defaults, err := mergeConsts(res.Defaults, c.Defaults)
+		if err != nil {
+			return res, errors.Wrap(err, "error merging defaults")
+		}
+		res.Defaults = defaults
+		res.InterpolateEnv = res.InterpolateEnv || c.InterpolateEnv
+		res.Rules = append(res.Rules, c.Rules...)
+	}
+	return res, nil
+}
+
+func mergeConsts(c1, c2 Consts) (Consts, error) {
+	if len(c2) == 0 {
+		return c1, nil
+	}
+	res := Consts{}
+	for k, v := range c1 {
+		res[k] = v
+	}
+
+	// Iterate in order, to get deterministic errors
+	var names []string
+	for k := range c2 {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	for _, k := range names {
+		v := c2[k]
+		if old, ok := res[k]; ok && !reflect.DeepEqual(old, v) {
+			return nil, errors.Errorf("conflicting definitions for const '%s'", k)
+		}
+		res[k] = v
+	}
+	return res, nil
+}
@@ -0,0 +1,61 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeConfigs(t *testing.T) {
+	c1 := Config{
+		Version: Version,
+		Author:  Author{Name: "Me", Email: "me@gmail.com"},
+		Consts: Consts{
+			"friends": {Values: []string{"a@gmail.com"}},
+			"spam":    {Values: []string{"spam@gmail.com"}},
+		},
+		Rules: []Rule{
+			{
+				Filters: Filters{Consts: CompositeFilters{MatchFilters: MatchFilters{From: []string{"friends"}}}},
+				Actions: Actions{MarkImportant: true},
+			},
+		},
+	}
+	c2 := Config{
+		Consts: Consts{
+			"spam": {Values: []string{"spam@gmail.com"}},
+			"work": {Values: []string{"@work.com"}},
+		},
+		Rules: []Rule{
+			{
+				Filters: Filters{Consts: CompositeFilters{MatchFilters: MatchFilters{From: []string{"work"}}}},
+				Actions: Actions{Labels: []string{"work"}},
+			},
+		},
+	}
+
+	got, err := MergeConfigs(c1, c2)
+	assert.Nil(t, err)
+	expected := Config{
+		Version: Version,
+		Author:  Author{Name: "Me", Email: "me@gmail.com"},
+		Consts: Consts{
+			"friends": {Values: []string{"a@gmail.com"}},
+			"spam":    {Values: []string{"spam@gmail.com"}},
+			"work":    {Values: []string{"@work.com"}},
+		},
+		Rules: []Rule{c1.Rules[0], c2.Rules[0]},
+	}
+	assert.Equal(t, expected, got)
+}
+
+func TestMergeConfigsConflict(t *testing.T) {
+	c1 := Config{
+		Consts: Consts{"friends": {Values: []string{"a@gmail.com"}}},
+	}
+	c2 := Config{
+		Consts: Consts{"friends": {Values: []string{"b@gmail.com"}}},
+	}
+	_, err := MergeConfigs(c1, c2)
+	assert.EqualError(t, err, "error merging consts: conflicting definitions for const 'friends'")
+}
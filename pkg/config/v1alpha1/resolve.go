@@ -1,16 +1,34 @@
 package v1alpha1
 
 import (
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
 	"github.com/pkg/errors"
+
+	"github.com/mbrt/gmailctl/pkg/gmail"
 )
 
 // ResolveConsts returns a copy of the config with all the constants
 // replaced in the filters.
+//
+// Constants not present in the config are looked up in the defaults.
+// If enabled, environment variables are substituted in their values.
 func ResolveConsts(c Config) (Config, error) {
+	consts := withDefaults(c.Consts, c.Defaults)
+	if c.InterpolateEnv {
+		var err error
+		if consts, err = interpolateEnv(consts, os.LookupEnv); err != nil {
+			return c, err
+		}
+	}
+
 	// Don't touch the original, copy the rules
 	var rules []Rule
 	for i, r := range c.Rules {
-		f, err := resolveFilters(r.Filters, c.Consts)
+		f, err := resolveFilters(r.Filters, consts)
 		if err != nil {
 			return c, errors.Wrapf(err, "error in rule #%d", i)
 		}
@@ -20,9 +38,64 @@ func ResolveConsts(c Config) (Config, error) {
 	c.Rules = rules
 	// Get rid of the constants
 	c.Consts = Consts{}
+	c.Defaults = nil
+	c.InterpolateEnv = false
 	return c, nil
 }
 
+// envRegexp matches references to environment variables, e.g. '${USER}'.
+var envRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// interpolateEnv returns a copy of the consts where the references to
+// environment variables are replaced by their values, obtained through
+// the given lookup function.
+func interpolateEnv(consts Consts, lookup func(string) (string, bool)) (Consts, error) {
+	// Iterate in order, to get deterministic errors
+	var names []string
+	for name := range consts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	res := Consts{}
+	for _, name := range names {
+		c := consts[name]
+		var values []string
+		for _, v := range c.Values {
+			var missing string
+			expanded := envRegexp.ReplaceAllStringFunc(v, func(ref string) string {
+				env := envRegexp.FindStringSubmatch(ref)[1]
+				value, ok := lookup(env)
+				if !ok && missing == "" {
+					missing = env
+				}
+				return value
+			})
+			if missing != "" {
+				return nil, errors.Errorf("error in const '%s': environment variable '%s' is not set",
+					name, missing)
+			}
+			values = append(values, expanded)
+		}
+		res[name] = ConstValue{Values: values, Consts: c.Consts}
+	}
+	return res, nil
+}
+
+func withDefaults(consts, defaults Consts) Consts {
+	if len(defaults) == 0 {
+		return consts
+	}
+	res := Consts{}
+	for k, v := range defaults {
+		res[k] = v
+	}
+	for k, v := range consts {
+		res[k] = v
+	}
+	return res
+}
+
 func resolveFilters(f Filters, consts Consts) (Filters, error) {
 	var res Filters
 
@@ -40,6 +113,14 @@ func resolveFilters(f Filters, consts Consts) (Filters, error) {
 	res.MatchFilters = joinMatchFilters(f.MatchFilters, cm)
 	res.Not = joinMatchFilters(f.Not, ncm)
 
+	for _, domains := range [][]string{res.FromDomain, res.Not.FromDomain} {
+		for _, d := range domains {
+			if !gmail.IsDomain(d) {
+				return res, errors.Errorf("invalid domain '%s' in 'fromDomain' clause", d)
+			}
+		}
+	}
+
 	return res, nil
 }
 
@@ -48,6 +129,10 @@ func resolveFiltersConsts(mf MatchFilters, consts Consts) (MatchFilters, error)
 	if err != nil {
 		return mf, errors.Wrap(err, "error in resolving 'from' clause")
 	}
+	fromDomain, err := resolveConsts(mf.FromDomain, consts)
+	if err != nil {
+		return mf, errors.Wrap(err, "error in resolving 'fromDomain' clause")
+	}
 	to, err := resolveConsts(mf.To, consts)
 	if err != nil {
 		return mf, errors.Wrap(err, "error in resolving 'to' clause")
@@ -56,6 +141,10 @@ func resolveFiltersConsts(mf MatchFilters, consts Consts) (MatchFilters, error)
 	if err != nil {
 		return mf, errors.Wrap(err, "error in resolving 'cc' clause")
 	}
+	bcc, err := resolveConsts(mf.Bcc, consts)
+	if err != nil {
+		return mf, errors.Wrap(err, "error in resolving 'bcc' clause")
+	}
 	sub, err := resolveConsts(mf.Subject, consts)
 	if err != nil {
 		return mf, errors.Wrap(err, "error in resolving 'subject' clause")
@@ -64,17 +153,24 @@ func resolveFiltersConsts(mf MatchFilters, consts Consts) (MatchFilters, error)
 	if err != nil {
 		return mf, errors.Wrap(err, "error in resolving 'has' clause")
 	}
+	hasAll, err := resolveConsts(mf.HasAll, consts)
+	if err != nil {
+		return mf, errors.Wrap(err, "error in resolving 'hasAll' clause")
+	}
 	list, err := resolveConsts(mf.List, consts)
 	if err != nil {
 		return mf, errors.Wrap(err, "error in resolving 'list' clause")
 	}
 	res := MatchFilters{
-		From:    from,
-		To:      to,
-		Cc:      cc,
-		Subject: sub,
-		Has:     has,
-		List:    list,
+		From:       from,
+		FromDomain: fromDomain,
+		To:         to,
+		Cc:         cc,
+		Bcc:        bcc,
+		Subject:    sub,
+		Has:        has,
+		HasAll:     hasAll,
+		List:       list,
 	}
 	return res, nil
 }
@@ -82,11 +178,131 @@ func resolveFiltersConsts(mf MatchFilters, consts Consts) (MatchFilters, error)
 func resolveConsts(a []string, consts Consts) ([]string, error) {
 	res := []string{}
 	for _, s := range a {
-		resolved, ok := consts[s]
-		if !ok {
-			return nil, errors.Errorf("failed to resolve const '%s'", s)
+		resolved, err := resolveConst(s, consts, nil)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, resolved...)
+	}
+	return res, nil
+}
+
+// resolveConst returns the values of the given const, including the ones of
+// the consts it refers to. The path contains the names of the consts
+// being resolved and it's used to detect cycles.
+func resolveConst(name string, consts Consts, path []string) ([]string, error) {
+	for _, p := range path {
+		if p == name {
+			cycle := append(path, name)
+			return nil, errors.Errorf("cycle detected in consts: %s",
+				strings.Join(cycle, " -> "))
+		}
+	}
+	c, ok := consts[name]
+	if !ok {
+		if s := suggestConst(name, consts); s != "" {
+			return nil, errors.Errorf("failed to resolve const '%s' (did you mean '%s'?)", name, s)
+		}
+		return nil, errors.Errorf("failed to resolve const '%s'", name)
+	}
+
+	res := []string{}
+	path = append(path, name)
+	for _, v := range c.Values {
+		expanded, err := expandTemplate(v, consts, path)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, expanded...)
+	}
+	for _, ref := range c.Consts {
+		resolved, err := resolveConst(ref, consts, path)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, resolved...)
+	}
+	return res, nil
+}
+
+// maxSuggestionDistance is the maximum edit distance between a missing
+// const name and the one suggested.
+const maxSuggestionDistance = 2
+
+// suggestConst returns the name of the const closest to the given one, or an
+// empty string if none is close enough.
+func suggestConst(name string, consts Consts) string {
+	res, best := "", maxSuggestionDistance+1
+	for c := range consts {
+		d := editDistance(strings.ToLower(name), strings.ToLower(c))
+		if strings.EqualFold(name, c) {
+			// A difference in case only is always the best candidate.
+			d = 0
+		}
+		if d < best || (d == best && c < res) {
+			res, best = c, d
+		}
+	}
+	return res
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, minInt(curr[j-1]+1, prev[j-1]+cost))
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// placeholderRegexp matches references to other consts inside values,
+// e.g. 'info@{{domain}}'.
+var placeholderRegexp = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// expandTemplate substitutes the placeholders in the given value with the
+// values of the referred consts. If multiple placeholders are present, all
+// the combinations of their values are returned.
+func expandTemplate(value string, consts Consts, path []string) ([]string, error) {
+	loc := placeholderRegexp.FindStringSubmatchIndex(value)
+	if loc == nil {
+		return []string{value}, nil
+	}
+	prefix, name, suffix := value[:loc[0]], value[loc[2]:loc[3]], value[loc[1]:]
+
+	values, err := resolveConst(name, consts, path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error expanding '%s'", value)
+	}
+	rest, err := expandTemplate(suffix, consts, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+	for _, v := range values {
+		for _, r := range rest {
+			res = append(res, prefix+v+r)
 		}
-		res = append(res, resolved.Values...)
 	}
 	return res, nil
 }
@@ -94,10 +310,13 @@ func resolveConsts(a []string, consts Consts) ([]string, error) {
 func joinMatchFilters(f1, f2 MatchFilters) MatchFilters {
 	res := MatchFilters{}
 	res.From = joinFilter(f1.From, f2.From)
+	res.FromDomain = joinFilter(f1.FromDomain, f2.FromDomain)
 	res.To = joinFilter(f1.To, f2.To)
 	res.Cc = joinFilter(f1.Cc, f2.Cc)
+	res.Bcc = joinFilter(f1.Bcc, f2.Bcc)
 	res.Subject = joinFilter(f1.Subject, f2.Subject)
 	res.Has = joinFilter(f1.Has, f2.Has)
+	res.HasAll = joinFilter(f1.HasAll, f2.HasAll)
 	res.List = joinFilter(f1.List, f2.List)
 	return res
 }
@@ -0,0 +1,186 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveNestedConsts(t *testing.T) {
+	consts := Consts{
+		"people":  {Values: []string{"a@work.com", "b@work.com"}},
+		"lists":   {Values: []string{"team@work.com"}},
+		"work":    {Consts: []string{"people", "lists"}},
+		"allWork": {Values: []string{"boss@work.com"}, Consts: []string{"work"}},
+	}
+	got, err := resolveConsts([]string{"allWork"}, consts)
+	assert.Nil(t, err)
+	expected := []string{
+		"boss@work.com",
+		"a@work.com",
+		"b@work.com",
+		"team@work.com",
+	}
+	assert.Equal(t, expected, got)
+}
+
+func TestResolveConstsCycle(t *testing.T) {
+	consts := Consts{
+		"self": {Values: []string{"a"}, Consts: []string{"self"}},
+		"a":    {Consts: []string{"b"}},
+		"b":    {Consts: []string{"a"}},
+	}
+	_, err := resolveConsts([]string{"self"}, consts)
+	assert.EqualError(t, err, "cycle detected in consts: self -> self")
+	_, err = resolveConsts([]string{"a"}, consts)
+	assert.EqualError(t, err, "cycle detected in consts: a -> b -> a")
+}
+
+func TestResolveConstsMissing(t *testing.T) {
+	consts := Consts{
+		"a": {Consts: []string{"missing"}},
+	}
+	_, err := resolveConsts([]string{"a"}, consts)
+	assert.EqualError(t, err, "failed to resolve const 'missing'")
+}
+
+func TestResolveConstsDefaults(t *testing.T) {
+	rule := Rule{
+		Filters: Filters{
+			Consts: CompositeFilters{
+				MatchFilters: MatchFilters{
+					From: []string{"friends", "optional"},
+				},
+			},
+		},
+		Actions: Actions{Archive: true},
+	}
+	consts := Consts{
+		"friends": {Values: []string{"a@gmail.com"}},
+	}
+
+	// Without defaults, the missing const is an error.
+	_, err := ResolveConsts(Config{Consts: consts, Rules: []Rule{rule}})
+	assert.NotNil(t, err)
+
+	// With a default it falls back to it.
+	c := Config{
+		Consts: consts,
+		Defaults: Consts{
+			"friends":  {Values: []string{"overridden@gmail.com"}},
+			"optional": {Values: []string{}},
+		},
+		Rules: []Rule{rule},
+	}
+	got, err := ResolveConsts(c)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a@gmail.com"}, got.Rules[0].Filters.From)
+	assert.Nil(t, got.Defaults)
+}
+
+func TestResolveConstsTemplate(t *testing.T) {
+	consts := Consts{
+		"domain": {Values: []string{"sales.com", "eng.com"}},
+		"user":   {Values: []string{"info", "help"}},
+		"single": {Values: []string{"noreply@{{domain}}"}},
+		"all":    {Values: []string{"{{ user }}@{{domain}}", "boss@work.com"}},
+	}
+	got, err := resolveConsts([]string{"single"}, consts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"noreply@sales.com", "noreply@eng.com"}, got)
+
+	got, err = resolveConsts([]string{"all"}, consts)
+	assert.Nil(t, err)
+	expected := []string{
+		"info@sales.com",
+		"info@eng.com",
+		"help@sales.com",
+		"help@eng.com",
+		"boss@work.com",
+	}
+	assert.Equal(t, expected, got)
+}
+
+func TestResolveConstsTemplateErrors(t *testing.T) {
+	consts := Consts{
+		"missing": {Values: []string{"a@{{nothere}}"}},
+		"cycle":   {Values: []string{"a@{{cycle}}"}},
+	}
+	_, err := resolveConsts([]string{"missing"}, consts)
+	assert.NotNil(t, err)
+	_, err = resolveConsts([]string{"cycle"}, consts)
+	assert.EqualError(t, err,
+		"error expanding 'a@{{cycle}}': cycle detected in consts: cycle -> cycle")
+}
+
+func TestResolveConstsSuggestion(t *testing.T) {
+	consts := Consts{
+		"workPeople": {Values: []string{"a@work.com"}},
+		"workLists":  {Values: []string{"list@work.com"}},
+		"friends":    {Values: []string{"b@gmail.com"}},
+	}
+	_, err := resolveConsts([]string{"workpeople"}, consts)
+	assert.EqualError(t, err, "failed to resolve const 'workpeople' (did you mean 'workPeople'?)")
+	_, err = resolveConsts([]string{"frends"}, consts)
+	assert.EqualError(t, err, "failed to resolve const 'frends' (did you mean 'friends'?)")
+	_, err = resolveConsts([]string{"family"}, consts)
+	assert.EqualError(t, err, "failed to resolve const 'family'")
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("abc", "abc"))
+	assert.Equal(t, 3, editDistance("", "abc"))
+	assert.Equal(t, 1, editDistance("frends", "friends"))
+	assert.Equal(t, 3, editDistance("kitten", "sitting"))
+}
+
+func TestResolveConstsEnv(t *testing.T) {
+	env := map[string]string{"DOMAIN": "work.com"}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	consts := Consts{
+		"work":  {Values: []string{"boss@${DOMAIN}", "${DOMAIN}"}, Consts: []string{"other"}},
+		"other": {Values: []string{"$DOMAIN"}},
+	}
+	got, err := interpolateEnv(consts, lookup)
+	assert.Nil(t, err)
+	expected := Consts{
+		"work":  {Values: []string{"boss@work.com", "work.com"}, Consts: []string{"other"}},
+		"other": {Values: []string{"$DOMAIN"}},
+	}
+	assert.Equal(t, expected, got)
+
+	consts = Consts{
+		"me": {Values: []string{"${UNSET_VAR}@gmail.com"}},
+	}
+	_, err = interpolateEnv(consts, lookup)
+	assert.EqualError(t, err, "error in const 'me': environment variable 'UNSET_VAR' is not set")
+}
+
+func TestResolveConstsEnvOptIn(t *testing.T) {
+	rule := Rule{
+		Filters: Filters{
+			Consts: CompositeFilters{
+				MatchFilters: MatchFilters{From: []string{"me"}},
+			},
+		},
+		Actions: Actions{Archive: true},
+	}
+	c := Config{
+		Consts: Consts{"me": {Values: []string{"${GMAILCTL_TEST_UNSET}"}}},
+		Rules:  []Rule{rule},
+	}
+
+	// Disabled by default
+	got, err := ResolveConsts(c)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"${GMAILCTL_TEST_UNSET}"}, got.Rules[0].Filters.From)
+
+	c.InterpolateEnv = true
+	_, err = ResolveConsts(c)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "environment variable 'GMAILCTL_TEST_UNSET' is not set")
+}
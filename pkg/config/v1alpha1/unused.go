@@ -0,0 +1,63 @@
+package v1alpha1
+
+import "sort"
+
+// UnusedConsts returns the sorted names of the consts that are never
+// referenced by any rule, either directly or through other consts.
+func UnusedConsts(c Config) []string {
+	used := map[string]bool{}
+	for _, r := range c.Rules {
+		for _, name := range matchFiltersValues(r.Filters.Consts.MatchFilters) {
+			markUsed(name, c.Consts, used)
+		}
+		for _, name := range matchFiltersValues(r.Filters.Consts.Not) {
+			markUsed(name, c.Consts, used)
+		}
+	}
+
+	res := []string{}
+	for name := range c.Consts {
+		if !used[name] {
+			res = append(res, name)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// markUsed marks the given const as used, together with all the consts it
+// refers to.
+func markUsed(name string, consts Consts, used map[string]bool) {
+	if used[name] {
+		return
+	}
+	used[name] = true
+	c, ok := consts[name]
+	if !ok {
+		return
+	}
+	for _, ref := range c.Consts {
+		markUsed(ref, consts, used)
+	}
+	for _, v := range c.Values {
+		for _, m := range placeholderRegexp.FindAllStringSubmatch(v, -1) {
+			markUsed(m[1], consts, used)
+		}
+	}
+}
+
+// matchFiltersValues returns all the values of the given filters, in the
+// same fields handled by resolveFiltersConsts.
+func matchFiltersValues(mf MatchFilters) []string {
+	var res []string
+	res = append(res, mf.From...)
+	res = append(res, mf.FromDomain...)
+	res = append(res, mf.To...)
+	res = append(res, mf.Cc...)
+	res = append(res, mf.Bcc...)
+	res = append(res, mf.Subject...)
+	res = append(res, mf.Has...)
+	res = append(res, mf.HasAll...)
+	res = append(res, mf.List...)
+	return res
+}
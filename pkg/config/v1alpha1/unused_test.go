@@ -0,0 +1,41 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnusedConsts(t *testing.T) {
+	cfg := Config{
+		Consts: Consts{
+			"friends": {Values: []string{"a@{{domain}}"}, Consts: []string{"family"}},
+			"family":  {Values: []string{"mom@gmail.com"}},
+			"domain":  {Values: []string{"gmail.com"}},
+			"spam":    {Values: []string{"spam@gmail.com"}},
+			"work":    {Values: []string{"@work.com"}},
+			"old":     {Values: []string{"old@gmail.com"}},
+		},
+		Rules: []Rule{
+			{
+				Filters: Filters{
+					Consts: CompositeFilters{
+						MatchFilters: MatchFilters{From: []string{"friends"}},
+						Not:          MatchFilters{To: []string{"work"}},
+					},
+				},
+				Actions: Actions{MarkImportant: true},
+			},
+			{
+				Filters: Filters{
+					// Plain values are not const references
+					CompositeFilters: CompositeFilters{
+						MatchFilters: MatchFilters{From: []string{"spam"}},
+					},
+				},
+				Actions: Actions{Delete: true},
+			},
+		},
+	}
+	assert.Equal(t, []string{"old", "spam"}, UnusedConsts(cfg))
+}
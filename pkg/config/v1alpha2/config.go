@@ -1,8 +1,12 @@
 package v1alpha2
 
 import (
+	"fmt"
 	"reflect"
 	"strings"
+	"time"
+
+	"github.com/pkg/errors"
 
 	v1 "github.com/mbrt/gmailctl/pkg/config/v1alpha1"
 	"github.com/mbrt/gmailctl/pkg/gmail"
@@ -17,6 +21,10 @@ type Config struct {
 	Author  Author        `yaml:"author,omitempty"`
 	Filters []NamedFilter `yaml:"filters,omitempty"`
 	Rules   []Rule        `yaml:"rules"`
+
+	// FirstMatch makes only the first matching rule apply to an email,
+	// instead of all of them. See ApplyFirstMatchSemantics.
+	FirstMatch bool `yaml:"firstMatch,omitempty"`
 }
 
 // NamedFilter represents a filter with a name.
@@ -41,13 +49,25 @@ type FilterNode struct {
 	Or  []FilterNode `yaml:"or,omitempty"`
 	Not *FilterNode  `yaml:"not,omitempty"`
 
-	From    string `yaml:"from,omitempty"`
-	To      string `yaml:"to,omitempty"`
-	Cc      string `yaml:"cc,omitempty"`
-	Subject string `yaml:"subject,omitempty"`
-	List    string `yaml:"list,omitempty"`
-	Has     string `yaml:"has,omitempty"`
-	Query   string `yaml:"query,omitempty"`
+	From          string `yaml:"from,omitempty"`
+	To            string `yaml:"to,omitempty"`
+	Cc            string `yaml:"cc,omitempty"`
+	Bcc           string `yaml:"bcc,omitempty"`
+	Subject       string `yaml:"subject,omitempty"`
+	List          string `yaml:"list,omitempty"`
+	DeliveredTo   string `yaml:"deliveredTo,omitempty"`
+	Larger        string `yaml:"larger,omitempty"`
+	Smaller       string `yaml:"smaller,omitempty"`
+	Filename      string `yaml:"filename,omitempty"`
+	HasAttachment bool   `yaml:"hasAttachment,omitempty"`
+	Is            string `yaml:"is,omitempty"`
+	In            string `yaml:"in,omitempty"`
+	OlderThan     string `yaml:"olderThan,omitempty"`
+	NewerThan     string `yaml:"newerThan,omitempty"`
+	Label         string `yaml:"label,omitempty"`
+	MessageID     string `yaml:"messageId,omitempty"`
+	Has           string `yaml:"has,omitempty"`
+	Query         string `yaml:"query,omitempty"`
 }
 
 // NonEmptyFields returns the names of the fields with a value.
@@ -75,6 +95,10 @@ func (f FilterNode) NonEmptyFields() []string {
 			if field.Pointer() == 0 {
 				continue
 			}
+		case reflect.Bool:
+			if !field.Bool() {
+				continue
+			}
 		}
 
 		res = append(res, name)
@@ -106,6 +130,10 @@ func (f FilterNode) Empty() bool {
 			if field.Pointer() == 0 {
 				continue
 			}
+		case reflect.Bool:
+			if !field.Bool() {
+				continue
+			}
 		}
 
 		count++
@@ -119,8 +147,124 @@ func (f FilterNode) Empty() bool {
 // For every email, if the filter applies correctly, then the specified actions
 // will be applied to it.
 type Rule struct {
-	Filter  FilterNode `yaml:"filter"`
-	Actions Actions    `yaml:"actions"`
+	// Name is an optional description of the rule, useful to recognize
+	// its filters when reviewing changes.
+	Name string `yaml:"name,omitempty"`
+	// Accounts restricts the rule to the given Gmail accounts. Rules
+	// without accounts apply to all of them.
+	Accounts []string `yaml:"accounts,omitempty"`
+	// Tags are optional labels grouping related rules, which allow to
+	// generate only some of them.
+	Tags []string `yaml:"tags,omitempty"`
+	// Enabled allows to turn off the rule without removing it, when set
+	// to false. Rules are enabled by default.
+	Enabled *bool `yaml:"enabled,omitempty"`
+	// ExpiresAt is an optional date, in the YYYY-MM-DD format, from which
+	// the rule is not applied anymore. See PruneExpired.
+	ExpiresAt string     `yaml:"expiresAt,omitempty"`
+	Filter    FilterNode `yaml:"filter"`
+	Actions   Actions    `yaml:"actions"`
+}
+
+// IsEnabled returns true unless the rule was explicitly disabled.
+func (r Rule) IsEnabled() bool {
+	return r.Enabled == nil || *r.Enabled
+}
+
+// Expiry returns the time from which the rule is expired, or the zero time if
+// the rule never expires. Dates are in the local time zone.
+func (r Rule) Expiry() (time.Time, error) {
+	if r.ExpiresAt == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.ParseInLocation(expiryLayout, r.ExpiresAt, time.Local)
+	if err != nil {
+		return t, errors.Errorf("invalid expiry date '%s', expected YYYY-MM-DD", r.ExpiresAt)
+	}
+	return t, nil
+}
+
+// expiryLayout is the format of the expiry dates of the rules.
+const expiryLayout = "2006-01-02"
+
+// AppliesTo returns true if the rule applies to the given account.
+func (r Rule) AppliesTo(account string) bool {
+	if len(r.Accounts) == 0 {
+		return true
+	}
+	for _, a := range r.Accounts {
+		if strings.EqualFold(a, account) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithoutDisabled returns a copy of the config without the disabled rules,
+// together with the number of rules removed.
+func (c Config) WithoutDisabled() (Config, int) {
+	var rules []Rule
+	for _, r := range c.Rules {
+		if r.IsEnabled() {
+			rules = append(rules, r)
+		}
+	}
+	disabled := len(c.Rules) - len(rules)
+	c.Rules = rules
+	return c, disabled
+}
+
+// PruneExpired returns a copy of the config where the rules expired at the
+// given time are disabled, together with their names. Rules without a name
+// are identified by their position. Rules are disabled rather than removed,
+// so that the others keep their position in errors and warnings.
+//
+// Gmail doesn't support expiring filters, so this needs to happen before
+// their generation. Rules with an invalid expiry date are kept, so that the
+// error is reported by the parser.
+func PruneExpired(config Config, now time.Time) (Config, []string) {
+	rules := make([]Rule, len(config.Rules))
+	var pruned []string
+	for i, r := range config.Rules {
+		rules[i] = r
+		if !r.IsEnabled() {
+			continue
+		}
+		t, err := r.Expiry()
+		if err != nil || t.IsZero() || now.Before(t) {
+			continue
+		}
+		rules[i] = r.disabled()
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i)
+		}
+		pruned = append(pruned, name)
+	}
+	config.Rules = rules
+	return config, pruned
+}
+
+// disabled returns a disabled copy of the rule.
+func (r Rule) disabled() Rule {
+	enabled := false
+	r.Enabled = &enabled
+	return r
+}
+
+// ForAccount returns a copy of the config where the rules not applying to
+// the given account are disabled. Like in PruneExpired, the rules keep their
+// position.
+func (c Config) ForAccount(account string) Config {
+	rules := make([]Rule, len(c.Rules))
+	for i, r := range c.Rules {
+		rules[i] = r
+		if !r.AppliesTo(account) {
+			rules[i] = r.disabled()
+		}
+	}
+	c.Rules = rules
+	return c
 }
 
 // Author represents the owner of the gmail account.
@@ -140,10 +284,24 @@ type Actions struct {
 
 	Category gmail.Category `yaml:"category,omitempty"`
 	Labels   []string       `yaml:"labels,omitempty"`
+	// RemoveLabels are the labels to be removed from the emails. This is
+	// supported only when applying the filters through the Gmail API.
+	RemoveLabels []string `yaml:"removeLabels,omitempty"`
+
+	// Forward is the address the emails will be forwarded to.
+	// Gmail requires the address to be verified in the forwarding settings.
+	Forward string `yaml:"forward,omitempty"`
 }
 
 // Empty returns true if no actions are specified.
 func (a Actions) Empty() bool {
+	// An empty list of labels is equivalent to no labels.
+	if len(a.Labels) == 0 {
+		a.Labels = nil
+	}
+	if len(a.RemoveLabels) == 0 {
+		a.RemoveLabels = nil
+	}
 	return reflect.DeepEqual(a, Actions{})
 }
 
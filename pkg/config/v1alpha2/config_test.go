@@ -0,0 +1,84 @@
+package v1alpha2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPruneExpired(t *testing.T) {
+	now, _ := time.ParseInLocation("2006/01/02 15:04", "2019/03/08 17:00", time.Local)
+	cfg := Config{
+		Version: Version,
+		Rules: []Rule{
+			{
+				Name:      "out of office",
+				ExpiresAt: "2019-03-01",
+				Filter:    FilterNode{From: "a@x.com"},
+				Actions:   Actions{Archive: true},
+			},
+			{
+				ExpiresAt: "2019-03-08",
+				Filter:    FilterNode{From: "b@x.com"},
+				Actions:   Actions{Archive: true},
+			},
+			{
+				Name:      "project",
+				ExpiresAt: "2019-03-09",
+				Filter:    FilterNode{From: "c@x.com"},
+				Actions:   Actions{Archive: true},
+			},
+			{
+				Filter:  FilterNode{From: "d@x.com"},
+				Actions: Actions{Archive: true},
+			},
+		},
+	}
+
+	got, pruned := PruneExpired(cfg, now)
+	assert.Equal(t, []string{"out of office", "#1"}, pruned)
+	assert.Len(t, got.Rules, 4)
+	assert.False(t, got.Rules[0].IsEnabled())
+	assert.False(t, got.Rules[1].IsEnabled())
+	assert.Equal(t, cfg.Rules[2:], got.Rules[2:])
+	// The original config is left untouched
+	assert.True(t, cfg.Rules[0].IsEnabled())
+}
+
+func TestPruneExpiredDisabled(t *testing.T) {
+	disabled := false
+	cfg := Config{
+		Rules: []Rule{
+			{Enabled: &disabled},
+			{Enabled: &disabled, ExpiresAt: "2019-03-01"},
+			{ExpiresAt: "2019-03-01"},
+		},
+	}
+	// Rules already disabled are not reported, but they still count in the
+	// position of the others.
+	_, pruned := PruneExpired(cfg, time.Now())
+	assert.Equal(t, []string{"#2"}, pruned)
+}
+
+func TestPruneExpiredLocalTime(t *testing.T) {
+	defer func(l *time.Location) { time.Local = l }(time.Local)
+	time.Local = time.FixedZone("UTC+2", 2*60*60)
+
+	// Past midnight in the local time zone, but still the day before in UTC.
+	now := time.Date(2019, 3, 8, 0, 30, 0, 0, time.Local)
+	cfg := Config{Rules: []Rule{{ExpiresAt: "2019-03-08"}}}
+	_, pruned := PruneExpired(cfg, now)
+	assert.Equal(t, []string{"#0"}, pruned)
+}
+
+func TestExpiryInvalid(t *testing.T) {
+	r := Rule{ExpiresAt: "08/03/2019"}
+	_, err := r.Expiry()
+	assert.NotNil(t, err)
+
+	// Invalid rules are not pruned
+	cfg, pruned := PruneExpired(Config{Rules: []Rule{r}}, time.Now())
+	assert.Empty(t, pruned)
+	assert.Equal(t, []Rule{r}, cfg.Rules)
+}
@@ -0,0 +1,39 @@
+package v1alpha2
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ApplyFirstMatchSemantics rewrites the rules of the given config so that
+// only the first matching rule applies to an email.
+//
+// Gmail applies all the filters matching an email. To emulate the first
+// match semantics, every rule is changed to exclude the emails matched by
+// the rules preceding it, by adding the negation of their filters. This is
+// the same as using 'chainFilters' from the Jsonnet library. Disabled rules
+// are left untouched and don't affect the following ones.
+func ApplyFirstMatchSemantics(config Config) (Config, error) {
+	var negated []FilterNode
+	rules := make([]Rule, len(config.Rules))
+
+	for i, r := range config.Rules {
+		rules[i] = r
+		if !r.IsEnabled() {
+			continue
+		}
+		if r.Filter.Empty() {
+			return config, errors.Errorf("rule #%d: no filter specified", i)
+		}
+		if len(negated) > 0 {
+			and := make([]FilterNode, 0, len(negated)+1)
+			and = append(and, negated...)
+			rules[i].Filter = FilterNode{And: append(and, r.Filter)}
+		}
+		f := r.Filter
+		negated = append(negated, FilterNode{Not: &f})
+	}
+
+	config.Rules = rules
+	config.FirstMatch = false
+	return config, nil
+}
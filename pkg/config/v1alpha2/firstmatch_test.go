@@ -0,0 +1,99 @@
+package v1alpha2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFirstMatchSemantics(t *testing.T) {
+	cfg := Config{
+		Version:    Version,
+		FirstMatch: true,
+		Rules: []Rule{
+			{
+				Filter: FilterNode{
+					And: []FilterNode{
+						{From: "boss@work.com"},
+						{Subject: "urgent"},
+					},
+				},
+				Actions: Actions{Labels: []string{"urgent"}},
+			},
+			{
+				Filter:  FilterNode{From: "boss@work.com"},
+				Actions: Actions{Archive: true},
+			},
+		},
+	}
+	expected := Config{
+		Version: Version,
+		Rules: []Rule{
+			cfg.Rules[0],
+			{
+				Filter: FilterNode{
+					And: []FilterNode{
+						{
+							Not: &FilterNode{
+								And: []FilterNode{
+									{From: "boss@work.com"},
+									{Subject: "urgent"},
+								},
+							},
+						},
+						{From: "boss@work.com"},
+					},
+				},
+				Actions: Actions{Archive: true},
+			},
+		},
+	}
+
+	got, err := ApplyFirstMatchSemantics(cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+	// The original config is left untouched
+	assert.Equal(t, FilterNode{From: "boss@work.com"}, cfg.Rules[1].Filter)
+}
+
+func TestFirstMatchSemanticsEmptyFilter(t *testing.T) {
+	cfg := Config{
+		Version: Version,
+		Rules: []Rule{
+			{Actions: Actions{Archive: true}},
+			{
+				Filter:  FilterNode{From: "boss@work.com"},
+				Actions: Actions{Star: true},
+			},
+		},
+	}
+	_, err := ApplyFirstMatchSemantics(cfg)
+	assert.NotNil(t, err)
+}
+
+func TestFirstMatchSemanticsDisabled(t *testing.T) {
+	disabled := false
+	cfg := Config{
+		Version:    Version,
+		FirstMatch: true,
+		Rules: []Rule{
+			{
+				Enabled: &disabled,
+				Actions: Actions{Archive: true},
+			},
+			{
+				Filter:  FilterNode{From: "boss@work.com"},
+				Actions: Actions{Star: true},
+			},
+		},
+	}
+	expected := Config{
+		Version: Version,
+		Rules:   cfg.Rules,
+	}
+
+	// Disabled rules are neither checked nor negated in the following ones.
+	got, err := ApplyFirstMatchSemantics(cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
@@ -65,11 +65,19 @@ func convertMatchFilter(f v1.MatchFilters) FilterNode {
 	// This filter is an 'and' of operators, where each of them is an 'or'.
 	var res FilterNode
 
-	res = and(res, convertOperand(f.From, func(o string) FilterNode { return FilterNode{From: o} }))
+	// Domains are matched by Gmail with a plain 'from', so they go in the
+	// same 'or'.
+	from := append(append([]string{}, f.From...), f.FromDomain...)
+	res = and(res, convertOperand(from, func(o string) FilterNode { return FilterNode{From: o} }))
 	res = and(res, convertOperand(f.To, func(o string) FilterNode { return FilterNode{To: o} }))
 	res = and(res, convertOperand(f.Cc, func(o string) FilterNode { return FilterNode{Cc: o} }))
+	res = and(res, convertOperand(f.Bcc, func(o string) FilterNode { return FilterNode{Bcc: o} }))
 	res = and(res, convertOperand(f.Subject, func(o string) FilterNode { return FilterNode{Subject: o} }))
 	res = and(res, convertOperand(f.Has, func(o string) FilterNode { return FilterNode{Has: o} }))
+	// Differently from the other operands, these are all required.
+	for _, o := range f.HasAll {
+		res = and(res, FilterNode{Has: o})
+	}
 	res = and(res, convertOperand(f.List, func(o string) FilterNode { return FilterNode{List: o} }))
 
 	return res
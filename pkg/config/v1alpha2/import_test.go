@@ -95,3 +95,121 @@ func TestWrongConsts(t *testing.T) {
 	_, err := Import(v1c)
 	assert.NotNil(t, err)
 }
+
+func TestEmptyConsts(t *testing.T) {
+	// Consts expanding to nothing produce an empty filter, which is
+	// rejected by the parser, instead of matching all emails.
+	cfg := v1.Config{
+		Consts: v1.Consts{
+			"nobody": {Values: []string{}},
+		},
+		Rules: []v1.Rule{
+			{
+				Filters: v1.Filters{
+					Consts: v1.CompositeFilters{
+						MatchFilters: v1.MatchFilters{
+							From: []string{"nobody"},
+						},
+					},
+				},
+				Actions: v1.Actions{Delete: true},
+			},
+		},
+	}
+	res, err := Import(cfg)
+	assert.Nil(t, err)
+	assert.True(t, res.Rules[0].Filter.Empty())
+}
+
+func importMatchFilters(t *testing.T, mf v1.MatchFilters) FilterNode {
+	t.Helper()
+	cfg := v1.Config{
+		Rules: []v1.Rule{
+			{
+				Filters: v1.Filters{
+					CompositeFilters: v1.CompositeFilters{MatchFilters: mf},
+				},
+				Actions: v1.Actions{Archive: true},
+			},
+		},
+	}
+	res, err := Import(cfg)
+	assert.Nil(t, err)
+	return res.Rules[0].Filter
+}
+
+func TestFromDomain(t *testing.T) {
+	got := importMatchFilters(t, v1.MatchFilters{
+		FromDomain: []string{"example.com"},
+	})
+	assert.Equal(t, FilterNode{From: "example.com"}, got)
+
+	got = importMatchFilters(t, v1.MatchFilters{
+		FromDomain: []string{"example.com", "mail.example.org"},
+	})
+	expected := FilterNode{
+		Or: []FilterNode{
+			{From: "example.com"},
+			{From: "mail.example.org"},
+		},
+	}
+	assert.Equal(t, expected, got)
+}
+
+func TestFromDomainWithAddresses(t *testing.T) {
+	got := importMatchFilters(t, v1.MatchFilters{
+		From:       []string{"boss@work.com"},
+		FromDomain: []string{"example.com"},
+		Subject:    []string{"urgent"},
+	})
+	expected := FilterNode{
+		And: []FilterNode{
+			{
+				Or: []FilterNode{
+					{From: "boss@work.com"},
+					{From: "example.com"},
+				},
+			},
+			{Subject: "urgent"},
+		},
+	}
+	assert.Equal(t, expected, got)
+}
+
+func TestFromDomainInvalid(t *testing.T) {
+	for _, d := range []string{"@example.com", "a@example.com", "example", "exa mple.com"} {
+		cfg := v1.Config{
+			Rules: []v1.Rule{
+				{
+					Filters: v1.Filters{
+						CompositeFilters: v1.CompositeFilters{
+							MatchFilters: v1.MatchFilters{FromDomain: []string{d}},
+						},
+					},
+				},
+			},
+		}
+		_, err := Import(cfg)
+		assert.NotNil(t, err, d)
+	}
+}
+
+func TestHasAll(t *testing.T) {
+	got := importMatchFilters(t, v1.MatchFilters{
+		Has:    []string{"foo", "bar"},
+		HasAll: []string{"phrase a", "phrase b"},
+	})
+	expected := FilterNode{
+		And: []FilterNode{
+			{
+				Or: []FilterNode{
+					{Has: "foo"},
+					{Has: "bar"},
+				},
+			},
+			{Has: "phrase a"},
+			{Has: "phrase b"},
+		},
+	}
+	assert.Equal(t, expected, got)
+}
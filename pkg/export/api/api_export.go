@@ -2,6 +2,7 @@ package api
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 	gmailv1 "google.golang.org/api/gmail/v1"
@@ -76,10 +77,18 @@ func (de defaultExporter) exportAction(action filter.Actions, lmap LabelMap) (*g
 		}
 		lops.AddLabel(id)
 	}
+	if action.RemoveLabel != "" {
+		id, ok := lmap.NameToID(action.RemoveLabel)
+		if !ok {
+			return nil, errors.Errorf("label '%s' not found", action.RemoveLabel)
+		}
+		lops.RemoveLabel(id)
+	}
 
 	return &gmailv1.FilterAction{
 		AddLabelIds:    lops.addLabels,
 		RemoveLabelIds: lops.removeLabels,
+		Forward:        action.Forward,
 	}, nil
 }
 
@@ -120,7 +129,9 @@ func (de defaultExporter) exportCategory(category gmail.Category) (string, error
 	case gmail.CategoryPromotions:
 		return labelIDCategoryPromotions, nil
 	}
-	return "", errors.Errorf("unknown category '%s'", category)
+	possib := gmail.PossibleCategoryValues()
+	return "", errors.Errorf("unknown category '%s' (possible values: %s)",
+		category, strings.Join(possib, ", "))
 }
 
 func (de defaultExporter) exportCriteria(criteria filter.Criteria) (*gmailv1.FilterCriteria, error) {
@@ -25,6 +25,7 @@ func TestExportActions(t *testing.T) {
 				MarkNotSpam:   true,
 				MarkImportant: true,
 				Category:      gmail.CategoryUpdates,
+				Forward:       "baz@bar.com",
 			},
 			Criteria: filter.Criteria{
 				From: "foo@bar.com",
@@ -46,6 +47,7 @@ func TestExportActions(t *testing.T) {
 					labelIDUnread,
 					labelIDSpam,
 				},
+				Forward: "baz@bar.com",
 			},
 			Criteria: &gmailv1.FilterCriteria{
 				From: "foo@bar.com",
@@ -163,3 +165,96 @@ func TestExportLabels(t *testing.T) {
 	_, err = DefaulExporter().Export(filters, emptyLabelMap())
 	assert.NotNil(t, err)
 }
+
+func TestExportUnknownCategory(t *testing.T) {
+	filters := filter.Filters{
+		{
+			Action: filter.Actions{
+				Category: "foo",
+			},
+			Criteria: filter.Criteria{
+				From: "foo@bar.com",
+			},
+		},
+	}
+	_, err := DefaulExporter().Export(filters, emptyLabelMap())
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "personal, social, updates, forums, promotions")
+}
+
+// fakeLabelMap resolves every label name, by deriving the ID from it.
+type fakeLabelMap struct{}
+
+func (fakeLabelMap) NameToID(name string) (string, bool) { return "id-" + name, true }
+func (fakeLabelMap) IDToName(id string) (string, bool)   { return "", false }
+
+func TestExportLabelResolver(t *testing.T) {
+	filters := filter.Filters{
+		{
+			Action: filter.Actions{
+				AddLabel: "Work/Boss",
+				Archive:  true,
+			},
+			Criteria: filter.Criteria{
+				From:    "boss@work.com",
+				Subject: "urgent",
+			},
+		},
+	}
+
+	exported, err := DefaulExporter().Export(filters, fakeLabelMap{})
+	expected := []*gmailv1.Filter{
+		{
+			Action: &gmailv1.FilterAction{
+				AddLabelIds:    []string{"id-Work/Boss"},
+				RemoveLabelIds: []string{labelIDInbox},
+			},
+			Criteria: &gmailv1.FilterCriteria{
+				From:    "boss@work.com",
+				Subject: "urgent",
+			},
+		},
+	}
+
+	assert.Nil(t, err)
+	assert.Equal(t, expected, exported)
+}
+
+func TestExportRemoveLabel(t *testing.T) {
+	filters := filter.Filters{
+		{
+			Action: filter.Actions{
+				AddLabel:    "done",
+				RemoveLabel: "todo",
+				MarkRead:    true,
+			},
+			Criteria: filter.Criteria{
+				From: "boss@work.com",
+			},
+		},
+	}
+
+	lmap := NewDefaultLabelMap(map[string]string{
+		"id-done": "done",
+		"id-todo": "todo",
+	})
+	exported, err := DefaulExporter().Export(filters, lmap)
+	expected := []*gmailv1.Filter{
+		{
+			Action: &gmailv1.FilterAction{
+				AddLabelIds:    []string{"id-done"},
+				RemoveLabelIds: []string{labelIDUnread, "id-todo"},
+			},
+			Criteria: &gmailv1.FilterCriteria{
+				From: "boss@work.com",
+			},
+		},
+	}
+
+	assert.Nil(t, err)
+	assert.Equal(t, expected, exported)
+
+	// Unknown labels are not silently dropped.
+	_, err = DefaulExporter().Export(filters, emptyLabelMap())
+	assert.NotNil(t, err)
+}
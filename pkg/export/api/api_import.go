@@ -65,10 +65,11 @@ func (di defaultImporter) importAction(action *gmailv1.FilterAction, lmap LabelM
 	if action == nil {
 		return res, errors.New("empty action")
 	}
+	res.Forward = action.Forward
 	if err := di.importAddLabels(&res, action.AddLabelIds, lmap); err != nil {
 		return res, err
 	}
-	err := di.importRemoveLabels(&res, action.RemoveLabelIds)
+	err := di.importRemoveLabels(&res, action.RemoveLabelIds, lmap)
 	return res, err
 }
 
@@ -102,7 +103,7 @@ func (di defaultImporter) importAddLabels(res *filter.Actions, addLabelIDs []str
 	return nil
 }
 
-func (di defaultImporter) importRemoveLabels(res *filter.Actions, removeLabelIDs []string) error {
+func (di defaultImporter) importRemoveLabels(res *filter.Actions, removeLabelIDs []string, lmap LabelMap) error {
 	for _, labelID := range removeLabelIDs {
 		switch labelID {
 		case labelIDInbox:
@@ -114,8 +115,16 @@ func (di defaultImporter) importRemoveLabels(res *filter.Actions, removeLabelIDs
 		case labelIDSpam:
 			res.MarkNotSpam = true
 		default:
-			// filters not added by us are not supported
-			return errors.Errorf("unupported label to remove '%s'", labelID)
+			// it should be a user label to remove
+			labelName, ok := lmap.IDToName(labelID)
+			if !ok {
+				// filters not added by us are not supported
+				return errors.Errorf("unupported label to remove '%s'", labelID)
+			}
+			if res.RemoveLabel != "" {
+				return errors.Errorf("multiple labels to remove: '%s', '%s'", labelName, res.RemoveLabel)
+			}
+			res.RemoveLabel = labelName
 		}
 	}
 	return nil
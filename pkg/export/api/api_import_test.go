@@ -25,6 +25,7 @@ func TestImportActions(t *testing.T) {
 					labelIDUnread,
 					labelIDSpam,
 				},
+				Forward: "baz@bar.com",
 			},
 			Criteria: &gmailv1.FilterCriteria{
 				From: "foo@bar.com",
@@ -42,6 +43,7 @@ func TestImportActions(t *testing.T) {
 				MarkNotSpam:   true,
 				MarkImportant: true,
 				Category:      gmail.CategoryUpdates,
+				Forward:       "baz@bar.com",
 			},
 			Criteria: filter.Criteria{
 				From: "foo@bar.com",
@@ -184,3 +186,96 @@ func TestImportBad(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Len(t, imported, 1)
 }
+
+func TestExportImportRoundTrip(t *testing.T) {
+	filters := filter.Filters{
+		{
+			Action: filter.Actions{
+				Archive:     true,
+				MarkRead:    true,
+				MarkNotSpam: true,
+				Category:    gmail.CategoryForums,
+				AddLabel:    "MyLabel",
+			},
+			Criteria: filter.Criteria{
+				From:  "foo@bar.com",
+				Query: "list:baz",
+			},
+		},
+		{
+			Action: filter.Actions{
+				Delete:           true,
+				MarkNotImportant: true,
+				Forward:          "me@bar.com",
+			},
+			Criteria: filter.Criteria{
+				To:      "me+spam@bar.com",
+				Subject: "buy now",
+			},
+		},
+	}
+	lmap := NewDefaultLabelMap(map[string]string{"label1": "MyLabel"})
+
+	exported, err := DefaulExporter().Export(filters, lmap)
+	assert.Nil(t, err)
+	imported, err := DefaulImporter().Import(exported, lmap)
+	assert.Nil(t, err)
+
+	for i := range filters {
+		assert.Equal(t, filter.Normalize(filters[i]), filter.Normalize(imported[i]))
+	}
+}
+
+func TestImportRemoveLabel(t *testing.T) {
+	filters := []*gmailv1.Filter{
+		{
+			Action: &gmailv1.FilterAction{
+				AddLabelIds:    []string{"id-done"},
+				RemoveLabelIds: []string{labelIDUnread, "id-todo"},
+			},
+			Criteria: &gmailv1.FilterCriteria{
+				From: "boss@work.com",
+			},
+		},
+	}
+	lmap := NewDefaultLabelMap(map[string]string{
+		"id-done": "done",
+		"id-todo": "todo",
+	})
+	imported, err := DefaulImporter().Import(filters, lmap)
+	expected := filter.Filters{
+		{
+			Action: filter.Actions{
+				AddLabel:    "done",
+				RemoveLabel: "todo",
+				MarkRead:    true,
+			},
+			Criteria: filter.Criteria{
+				From: "boss@work.com",
+			},
+		},
+	}
+
+	assert.Nil(t, err)
+	assert.Equal(t, expected, imported)
+}
+
+func TestImportRemoveMultipleLabels(t *testing.T) {
+	filters := []*gmailv1.Filter{
+		{
+			Action: &gmailv1.FilterAction{
+				RemoveLabelIds: []string{"id-todo", "id-later"},
+			},
+			Criteria: &gmailv1.FilterCriteria{
+				From: "boss@work.com",
+			},
+		},
+	}
+	lmap := NewDefaultLabelMap(map[string]string{
+		"id-todo":  "todo",
+		"id-later": "later",
+	})
+	_, err := DefaulImporter().Import(filters, lmap)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "multiple labels to remove: 'later', 'todo'")
+}
@@ -0,0 +1,77 @@
+package json
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/mbrt/gmailctl/pkg/filter"
+)
+
+// Exporter exports the given filters to JSON.
+type Exporter interface {
+	// Export exports Gmail filters into a JSON array.
+	//
+	// Every filter is represented by an object, mapping the names of its
+	// criteria and actions to their values. Empty values are omitted.
+	Export(filters filter.Filters, w io.Writer) error
+}
+
+// DefaultExporter returns a default implementation of the Exporter interface.
+func DefaultExporter() Exporter {
+	return jsonExporter{}
+}
+
+type jsonExporter struct{}
+
+func (j jsonExporter) Export(filters filter.Filters, w io.Writer) error {
+	entries := make([]map[string]interface{}, len(filters))
+	for i, f := range filters {
+		entries[i] = filterToJSON(f)
+	}
+	// Maps are encoded with sorted keys, so the output is deterministic.
+	b, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "error marshalling filters")
+	}
+	if _, err = w.Write(b); err != nil {
+		return errors.Wrap(err, "error writing filters")
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+func filterToJSON(f filter.Filter) map[string]interface{} {
+	res := map[string]interface{}{}
+	addString := func(name, value string) {
+		if value != "" {
+			res[name] = value
+		}
+	}
+	addBool := func(name string, value bool) {
+		if value {
+			res[name] = true
+		}
+	}
+
+	addString("name", f.Name)
+	addString("from", f.Criteria.From)
+	addString("to", f.Criteria.To)
+	addString("subject", f.Criteria.Subject)
+	addString("query", f.Criteria.Query)
+
+	addString("label", f.Action.AddLabel)
+	addString("removeLabel", f.Action.RemoveLabel)
+	addString("category", string(f.Action.Category))
+	addString("forward", f.Action.Forward)
+	addBool("archive", f.Action.Archive)
+	addBool("delete", f.Action.Delete)
+	addBool("markImportant", f.Action.MarkImportant)
+	addBool("markNotImportant", f.Action.MarkNotImportant)
+	addBool("markRead", f.Action.MarkRead)
+	addBool("markNotSpam", f.Action.MarkNotSpam)
+	addBool("star", f.Action.Star)
+
+	return res
+}
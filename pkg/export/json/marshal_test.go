@@ -0,0 +1,66 @@
+package json
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mbrt/gmailctl/pkg/filter"
+	"github.com/mbrt/gmailctl/pkg/gmail"
+)
+
+// update is useful to regenerate the golden files, whenever necessary.
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGolden(t *testing.T) {
+	filters := filter.Filters{
+		{
+			Name: "Golang nuts",
+			Action: filter.Actions{
+				Archive:     true,
+				MarkRead:    true,
+				MarkNotSpam: true,
+				Category:    gmail.CategoryForums,
+				AddLabel:    "lists/golang",
+			},
+			Criteria: filter.Criteria{
+				Query: "list:golang-nuts@googlegroups.com",
+			},
+		},
+		{
+			Action: filter.Actions{
+				Delete:           true,
+				MarkNotImportant: true,
+				Forward:          "me@mail.com",
+			},
+			Criteria: filter.Criteria{
+				From:    "{spammer@mail.com other@mail.com}",
+				To:      "me@mail.com",
+				Subject: `"buy now"`,
+			},
+		},
+	}
+	buf := new(bytes.Buffer)
+	err := DefaultExporter().Export(filters, buf)
+	assert.Nil(t, err)
+
+	golden := "testdata/golden.json"
+	if *update {
+		err = ioutil.WriteFile(golden, buf.Bytes(), 0644)
+		assert.Nil(t, err)
+		return
+	}
+	expected, err := ioutil.ReadFile(golden)
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), buf.String())
+}
+
+func TestEmpty(t *testing.T) {
+	buf := new(bytes.Buffer)
+	err := DefaultExporter().Export(filter.Filters{}, buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "[]\n", buf.String())
+}
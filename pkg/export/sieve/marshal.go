@@ -0,0 +1,353 @@
+package sieve
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mbrt/gmailctl/pkg/filter"
+)
+
+// Exporter exports the given filters to a Sieve script (RFC 5228).
+type Exporter interface {
+	// Export exports Gmail filters into a Sieve script.
+	//
+	// The criteria are converted into 'header :contains' tests, while labels
+	// become folders. Actions without a Sieve equivalent (e.g. categories)
+	// are dropped and reported with a comment in the script. Queries are
+	// converted only if they consist of free text (matched in the body) and
+	// address, subject or 'list' operators, optionally negated; other
+	// filters with a query are skipped, with a comment as well.
+	Export(filters filter.Filters, w io.Writer) error
+}
+
+// DefaultExporter returns a default implementation of the Exporter interface.
+func DefaultExporter() Exporter {
+	return sieveExporter{}
+}
+
+// archiveFolder is the folder used for archived mail without a label.
+const archiveFolder = "Archive"
+
+type sieveExporter struct{}
+
+func (s sieveExporter) Export(filters filter.Filters, w io.Writer) error {
+	requires := map[string]struct{}{}
+	var blocks []string
+
+	for i, f := range filters {
+		b, err := filterToSieve(f, requires)
+		if err != nil {
+			return errors.Wrapf(err, "error exporting filter #%d", i)
+		}
+		blocks = append(blocks, b)
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString("# Generated by gmailctl. Do not edit.\n")
+	if len(requires) > 0 {
+		var exts []string
+		for r := range requires {
+			exts = append(exts, quote(r))
+		}
+		sort.Strings(exts)
+		fmt.Fprintf(&sb, "require [%s];\n", strings.Join(exts, ", "))
+	}
+	for _, b := range blocks {
+		sb.WriteRune('\n')
+		sb.WriteString(b)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return errors.Wrap(err, "error writing filters")
+}
+
+func filterToSieve(f filter.Filter, requires map[string]struct{}) (string, error) {
+	sb := strings.Builder{}
+	if f.Name != "" {
+		fmt.Fprintf(&sb, "# %s\n", f.Name)
+	}
+
+	var query string
+	if f.Criteria.Query != "" {
+		var err error
+		if query, err = queryToTest(f.Criteria.Query, requires); err != nil {
+			fmt.Fprintf(&sb, "# WARNING: filter with query '%s' is not supported in Sieve (%v) and was skipped\n",
+				f.Criteria.Query, err)
+			return sb.String(), nil
+		}
+	}
+	test, err := criteriaToTest(f.Criteria, query)
+	if err != nil {
+		return "", err
+	}
+	actions, warnings := actionsToSieve(f.Action, requires)
+
+	for _, w := range warnings {
+		fmt.Fprintf(&sb, "# WARNING: %s\n", w)
+	}
+	fmt.Fprintf(&sb, "if %s {\n", test)
+	for _, a := range actions {
+		fmt.Fprintf(&sb, "  %s;\n", a)
+	}
+	sb.WriteString("}\n")
+	return sb.String(), nil
+}
+
+// criteriaToTest converts the criteria into a Sieve test. The query is
+// converted separately, and its test is combined with the others.
+func criteriaToTest(c filter.Criteria, query string) (string, error) {
+	fields := []struct {
+		name    string
+		headers []string
+		value   string
+	}{
+		{"from", []string{"from"}, c.From},
+		// Gmail matches all the recipients in 'to'
+		{"to", []string{"to", "cc", "bcc"}, c.To},
+		{"subject", []string{"subject"}, c.Subject},
+	}
+
+	var tests []string
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		t, err := fieldToTest(f.headers, f.value)
+		if err != nil {
+			return "", errors.Wrapf(err, "error in '%s'", f.name)
+		}
+		tests = append(tests, t)
+	}
+	if query != "" {
+		tests = append(tests, query)
+	}
+
+	if len(tests) == 0 {
+		return "", errors.New("empty criteria")
+	}
+	return joinTests("allof", tests), nil
+}
+
+func fieldToTest(headers []string, value string) (string, error) {
+	terms, err := splitTerms(value)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot convert '%s'", value)
+	}
+	op := "allof"
+	if terms.or {
+		op = "anyof"
+	}
+
+	header := headerList(headers)
+	var tests []string
+	for _, t := range terms.values {
+		tests = append(tests, fmt.Sprintf("header :contains %s %s", header, quote(t)))
+	}
+	return joinTests(op, tests), nil
+}
+
+// queryHeaders maps the Gmail operators supported in queries to the headers
+// they match.
+var queryHeaders = map[string][]string{
+	"from":    {"from"},
+	"to":      {"to", "cc", "bcc"},
+	"cc":      {"cc"},
+	"bcc":     {"bcc"},
+	"subject": {"subject"},
+	"list":    {"list-id"},
+}
+
+// queryToTest converts a Gmail query into a Sieve test. Only free text,
+// matched in the body, the operators in queryHeaders and their negations are
+// supported.
+func queryToTest(query string, requires map[string]struct{}) (string, error) {
+	terms, or, err := filter.SplitGroup(query)
+	if err != nil {
+		return "", err
+	}
+	op := "allof"
+	if or {
+		op = "anyof"
+	}
+
+	var tests []string
+	for _, t := range terms {
+		test, err := queryTermToTest(t, requires)
+		if err != nil {
+			return "", err
+		}
+		tests = append(tests, test)
+	}
+	if len(tests) == 0 {
+		return "", errors.New("empty query")
+	}
+	return joinTests(op, tests), nil
+}
+
+func queryTermToTest(t filter.Term, requires map[string]struct{}) (string, error) {
+	if t.Quoted {
+		requires["body"] = struct{}{}
+		return fmt.Sprintf("body :text :contains %s", quote(t.Value)), nil
+	}
+	if strings.HasPrefix(t.Value, "-") {
+		test, err := queryTermToTest(filter.Term{Value: t.Value[1:]}, requires)
+		return "not " + test, err
+	}
+	parts := strings.SplitN(t.Value, ":", 2)
+	if len(parts) == 1 {
+		requires["body"] = struct{}{}
+		return fmt.Sprintf("body :text :contains %s", quote(t.Value)), nil
+	}
+	headers, ok := queryHeaders[strings.ToLower(parts[0])]
+	if !ok {
+		return "", errors.Errorf("operator '%s' is not supported", parts[0])
+	}
+	return fmt.Sprintf("header :contains %s %s", headerList(headers), quote(parts[1])), nil
+}
+
+// headerList returns the headers in the Sieve syntax.
+func headerList(headers []string) string {
+	var hs []string
+	for _, h := range headers {
+		hs = append(hs, quote(h))
+	}
+	if len(hs) == 1 {
+		return hs[0]
+	}
+	return fmt.Sprintf("[%s]", strings.Join(hs, ", "))
+}
+
+func joinTests(op string, tests []string) string {
+	if len(tests) == 1 {
+		return tests[0]
+	}
+	return fmt.Sprintf("%s (%s)", op, strings.Join(tests, ", "))
+}
+
+// fieldTerms are the values of a criteria field.
+type fieldTerms struct {
+	values []string
+	// or is true if any of the values is enough to match.
+	or bool
+}
+
+// splitTerms splits a criteria field value into its terms, e.g. '{a b}' or
+// '(a OR b)'.
+func splitTerms(value string) (fieldTerms, error) {
+	terms, or, err := filter.SplitGroup(value)
+	if err != nil {
+		return fieldTerms{}, err
+	}
+	res := fieldTerms{or: or}
+	for _, t := range terms {
+		if !t.Quoted && strings.HasPrefix(t.Value, "-") {
+			return fieldTerms{}, errors.New("negations are not supported")
+		}
+		res.values = append(res.values, t.Value)
+	}
+	if len(res.values) == 0 {
+		return fieldTerms{}, errors.New("empty value")
+	}
+	return res, nil
+}
+
+// actionsToSieve converts the actions into Sieve commands, returning a
+// warning for every action that is dropped.
+func actionsToSieve(a filter.Actions, requires map[string]struct{}) (actions, warnings []string) {
+	require := func(ext string) {
+		requires[ext] = struct{}{}
+	}
+	for _, d := range droppedActions(a) {
+		warnings = append(warnings, fmt.Sprintf("'%s' is not supported in Sieve and was dropped", d))
+	}
+
+	if a.Delete {
+		// Nothing else matters if the message is discarded
+		for _, d := range discardedActions(a) {
+			warnings = append(warnings, fmt.Sprintf("'%s' has no effect on discarded messages and was dropped", d))
+		}
+		return []string{"discard", "stop"}, warnings
+	}
+	if a.MarkRead {
+		require("imap4flags")
+		actions = append(actions, `addflag "\\Seen"`)
+	}
+	if a.Star {
+		require("imap4flags")
+		actions = append(actions, `addflag "\\Flagged"`)
+	}
+	if a.Forward != "" {
+		require("copy")
+		actions = append(actions, fmt.Sprintf("redirect :copy %s", quote(a.Forward)))
+	}
+
+	switch {
+	case a.AddLabel != "" && a.Archive:
+		require("fileinto")
+		actions = append(actions, fmt.Sprintf("fileinto %s", quote(a.AddLabel)))
+	case a.AddLabel != "":
+		// The message stays in the inbox as well
+		require("fileinto")
+		require("copy")
+		actions = append(actions, fmt.Sprintf("fileinto :copy %s", quote(a.AddLabel)))
+	case a.Archive:
+		require("fileinto")
+		actions = append(actions, fmt.Sprintf("fileinto %s", quote(archiveFolder)))
+	}
+
+	return actions, warnings
+}
+
+// discardedActions returns the names of the supported actions made useless
+// by discarding the message.
+func discardedActions(a filter.Actions) []string {
+	var res []string
+	if a.Archive {
+		res = append(res, "archive")
+	}
+	if a.MarkRead {
+		res = append(res, "mark as read")
+	}
+	if a.Star {
+		res = append(res, "star")
+	}
+	if a.AddLabel != "" {
+		res = append(res, fmt.Sprintf("apply label %s", a.AddLabel))
+	}
+	if a.Forward != "" {
+		res = append(res, fmt.Sprintf("forward to %s", a.Forward))
+	}
+	return res
+}
+
+// droppedActions returns the names of the actions without a Sieve
+// equivalent.
+func droppedActions(a filter.Actions) []string {
+	var res []string
+	if a.Category != "" {
+		res = append(res, fmt.Sprintf("category %s", a.Category))
+	}
+	if a.RemoveLabel != "" {
+		res = append(res, fmt.Sprintf("remove label %s", a.RemoveLabel))
+	}
+	if a.MarkImportant {
+		res = append(res, "mark as important")
+	}
+	if a.MarkNotImportant {
+		res = append(res, "never mark as important")
+	}
+	if a.MarkNotSpam {
+		res = append(res, "never mark as spam")
+	}
+	return res
+}
+
+func quote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	return `"` + s + `"`
+}
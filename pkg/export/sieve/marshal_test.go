@@ -0,0 +1,240 @@
+package sieve
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mbrt/gmailctl/pkg/filter"
+	"github.com/mbrt/gmailctl/pkg/gmail"
+	"github.com/mbrt/gmailctl/pkg/parser"
+)
+
+// update is useful to regenerate the golden files, whenever necessary.
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGolden(t *testing.T) {
+	filters := filter.Filters{
+		{
+			Name: "Invoices",
+			Action: filter.Actions{
+				Archive:  true,
+				MarkRead: true,
+				AddLabel: "Work/Invoices",
+				Category: gmail.CategoryUpdates,
+			},
+			Criteria: filter.Criteria{
+				From:    "{billing@x.com invoices@y.com}",
+				Subject: `"new invoice"`,
+			},
+		},
+		{
+			Action: filter.Actions{
+				AddLabel: "Family",
+				Star:     true,
+				Forward:  "me@mail.com",
+			},
+			Criteria: filter.Criteria{
+				To: "family@mail.com",
+			},
+		},
+		{
+			Action: filter.Actions{
+				Delete:           true,
+				MarkNotImportant: true,
+			},
+			Criteria: filter.Criteria{
+				From: "spammer@mail.com",
+			},
+		},
+	}
+	buf := new(bytes.Buffer)
+	err := DefaultExporter().Export(filters, buf)
+	assert.Nil(t, err)
+
+	golden := "testdata/golden.sieve"
+	if *update {
+		err = ioutil.WriteFile(golden, buf.Bytes(), 0644)
+		assert.Nil(t, err)
+		return
+	}
+	expected, err := ioutil.ReadFile(golden)
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), buf.String())
+}
+
+func TestGoldenQuery(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionHas,
+				Grouping: parser.OperationOr,
+				Args:     []string{"buy this", "buy that"},
+			},
+			Actions: parser.Actions{Delete: true},
+		},
+		{
+			Criteria: &parser.Node{
+				Operation: parser.OperationAnd,
+				Children: []parser.CriteriaAST{
+					&parser.Leaf{
+						Function: parser.FunctionList,
+						Args:     []string{"foobar@list.com"},
+					},
+					&parser.Leaf{
+						Function: parser.FunctionFrom,
+						Args:     []string{"boss@work.com"},
+					},
+				},
+			},
+			Actions: parser.Actions{Labels: []string{"mylist"}},
+		},
+		{
+			Criteria: &parser.Node{
+				Operation: parser.OperationAnd,
+				Children: []parser.CriteriaAST{
+					&parser.Node{
+						Operation: parser.OperationNot,
+						Children: []parser.CriteriaAST{
+							&parser.Leaf{
+								Function: parser.FunctionTo,
+								Args:     []string{"me@x.com"},
+							},
+						},
+					},
+					&parser.Leaf{
+						Function: parser.FunctionList,
+						Args:     []string{"foobar@list.com"},
+					},
+				},
+			},
+			Actions: parser.Actions{Archive: true},
+		},
+		{
+			// Other operators can't be converted
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionQuery,
+				Args:     []string{"has:attachment"},
+			},
+			Actions: parser.Actions{Archive: true},
+		},
+	}
+	filters, err := filter.FromRules(rules)
+	assert.Nil(t, err)
+	buf := new(bytes.Buffer)
+	err = DefaultExporter().Export(filters, buf)
+	assert.Nil(t, err)
+
+	golden := "testdata/query.sieve"
+	if *update {
+		err = ioutil.WriteFile(golden, buf.Bytes(), 0644)
+		assert.Nil(t, err)
+		return
+	}
+	expected, err := ioutil.ReadFile(golden)
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), buf.String())
+}
+
+func TestUnsupported(t *testing.T) {
+	tests := []struct {
+		criteria filter.Criteria
+		err      string
+	}{
+		{
+			criteria: filter.Criteria{From: "{a (b c)}"},
+			err:      "error in 'from': cannot convert '{a (b c)}': nested groups are not supported",
+		},
+		{
+			criteria: filter.Criteria{Subject: "-spam"},
+			err:      "error in 'subject': cannot convert '-spam': negations are not supported",
+		},
+		{
+			criteria: filter.Criteria{From: "(a OR b c)"},
+			err:      "error in 'from': cannot convert '(a OR b c)': mixing AND and OR is not supported",
+		},
+		{
+			criteria: filter.Criteria{From: "(a OR)"},
+			err:      "error in 'from': cannot convert '(a OR)': dangling OR",
+		},
+	}
+	for _, tc := range tests {
+		fs := filter.Filters{
+			{
+				Criteria: tc.criteria,
+				Action:   filter.Actions{Archive: true},
+			},
+		}
+		err := DefaultExporter().Export(fs, new(bytes.Buffer))
+		assert.EqualError(t, err, "error exporting filter #0: "+tc.err)
+	}
+}
+
+func TestOrKeyword(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Node{
+				Operation: parser.OperationAnd,
+				Children: []parser.CriteriaAST{
+					&parser.Leaf{
+						Function: parser.FunctionFrom,
+						Grouping: parser.OperationOr,
+						Args:     []string{"a@x.com", "b@x.com"},
+					},
+					&parser.Leaf{
+						Function: parser.FunctionSubject,
+						Grouping: parser.OperationAnd,
+						Args:     []string{"weekly", "report"},
+					},
+				},
+			},
+			Actions: parser.Actions{Archive: true},
+		},
+	}
+	fs, err := filter.FromRulesWithOptions(rules, filter.GenerateOptions{OrStyle: filter.OrKeyword})
+	assert.Nil(t, err)
+	assert.Equal(t, "(a@x.com OR b@x.com)", fs[0].Criteria.From)
+
+	buf := new(bytes.Buffer)
+	err = DefaultExporter().Export(fs, buf)
+	assert.Nil(t, err)
+	expected := `if allof (anyof (header :contains "from" "a@x.com", header :contains "from" "b@x.com"), ` +
+		`allof (header :contains "subject" "weekly", header :contains "subject" "report")) {`
+	assert.Contains(t, buf.String(), expected)
+}
+
+func TestDeleteDropsActions(t *testing.T) {
+	fs := filter.Filters{
+		{
+			Criteria: filter.Criteria{From: "spammer@mail.com"},
+			Action: filter.Actions{
+				Delete:   true,
+				MarkRead: true,
+				Star:     true,
+				AddLabel: "spam",
+				Forward:  "me@mail.com",
+				Category: gmail.CategoryPromotions,
+			},
+		},
+	}
+	buf := new(bytes.Buffer)
+	err := DefaultExporter().Export(fs, buf)
+	assert.Nil(t, err)
+
+	// Every dropped action is reported.
+	expected := `# WARNING: 'category promotions' is not supported in Sieve and was dropped
+# WARNING: 'mark as read' has no effect on discarded messages and was dropped
+# WARNING: 'star' has no effect on discarded messages and was dropped
+# WARNING: 'apply label spam' has no effect on discarded messages and was dropped
+# WARNING: 'forward to me@mail.com' has no effect on discarded messages and was dropped
+if header :contains "from" "spammer@mail.com" {
+  discard;
+  stop;
+}
+`
+	assert.Contains(t, buf.String(), expected)
+	assert.NotContains(t, buf.String(), "require")
+}
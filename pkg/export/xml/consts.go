@@ -9,23 +9,104 @@ import (
 	"github.com/mbrt/gmailctl/pkg/gmail"
 )
 
+// PropertyName is the name of a property of a filter in the Gmail XML
+// format.
+type PropertyName string
+
 // Property values
 const (
-	PropertyFrom             = "from"
-	PropertyTo               = "to"
-	PropertySubject          = "subject"
-	PropertyHas              = "hasTheWord"
-	PropertyMarkImportant    = "shouldAlwaysMarkAsImportant"
-	PropertyMarkNotImportant = "shouldNeverMarkAsImportant"
-	PropertyApplyLabel       = "label"
-	PropertyApplyCategory    = "smartLabelToApply"
-	PropertyDelete           = "shouldTrash"
-	PropertyArchive          = "shouldArchive"
-	PropertyMarkRead         = "shouldMarkAsRead"
-	PropertyMarkNotSpam      = "shouldNeverSpam"
-	PropertyStar             = "shouldStar"
+	PropertyFrom             PropertyName = "from"
+	PropertyTo               PropertyName = "to"
+	PropertySubject          PropertyName = "subject"
+	PropertyHas              PropertyName = "hasTheWord"
+	PropertyDoesNotHave      PropertyName = "doesNotHaveTheWord"
+	PropertyHasAttachment    PropertyName = "hasAttachment"
+	PropertySize             PropertyName = "size"
+	PropertySizeOperator     PropertyName = "sizeOperator"
+	PropertySizeUnit         PropertyName = "sizeUnit"
+	PropertyExcludeChats     PropertyName = "excludeChats"
+	PropertyMarkImportant    PropertyName = "shouldAlwaysMarkAsImportant"
+	PropertyMarkNotImportant PropertyName = "shouldNeverMarkAsImportant"
+	PropertyApplyLabel       PropertyName = "label"
+	PropertyApplyCategory    PropertyName = "smartLabelToApply"
+	PropertyDelete           PropertyName = "shouldTrash"
+	PropertyArchive          PropertyName = "shouldArchive"
+	PropertyMarkRead         PropertyName = "shouldMarkAsRead"
+	PropertyMarkNotSpam      PropertyName = "shouldNeverSpam"
+	PropertyStar             PropertyName = "shouldStar"
+	PropertyForward          PropertyName = "forwardTo"
 )
 
+// allProperties contains all the known property names.
+var allProperties = []PropertyName{
+	PropertyFrom,
+	PropertyTo,
+	PropertySubject,
+	PropertyHas,
+	PropertyDoesNotHave,
+	PropertyHasAttachment,
+	PropertySize,
+	PropertySizeOperator,
+	PropertySizeUnit,
+	PropertyExcludeChats,
+	PropertyMarkImportant,
+	PropertyMarkNotImportant,
+	PropertyApplyLabel,
+	PropertyApplyCategory,
+	PropertyDelete,
+	PropertyArchive,
+	PropertyMarkRead,
+	PropertyMarkNotSpam,
+	PropertyStar,
+	PropertyForward,
+}
+
+// propertyOrder is the order in which Gmail emits the properties of a
+// filter in its own export: criteria first, then the label and category,
+// the boolean actions and finally forwarding and the size.
+var propertyOrder = []PropertyName{
+	PropertyFrom,
+	PropertyTo,
+	PropertySubject,
+	PropertyHas,
+	PropertyDoesNotHave,
+	PropertyHasAttachment,
+	PropertyExcludeChats,
+	PropertyApplyLabel,
+	PropertyApplyCategory,
+	PropertyArchive,
+	PropertyMarkRead,
+	PropertyStar,
+	PropertyDelete,
+	PropertyMarkNotSpam,
+	PropertyMarkImportant,
+	PropertyMarkNotImportant,
+	PropertyForward,
+	PropertySize,
+	PropertySizeOperator,
+	PropertySizeUnit,
+}
+
+// Valid returns true if the property name is known.
+func (p PropertyName) Valid() bool {
+	for _, prop := range allProperties {
+		if p == prop {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePropertyName returns the property with the given name, or an error
+// if the name is not known.
+func ParsePropertyName(name string) (PropertyName, error) {
+	p := PropertyName(name)
+	if !p.Valid() {
+		return p, errors.Errorf("unknown property '%s'", name)
+	}
+	return p, nil
+}
+
 // SmartLabel values
 const (
 	SmartLabelPersonal     = "personal"
@@ -38,6 +119,9 @@ const (
 func categoryToSmartLabel(cat gmail.Category) (string, error) {
 	var smartl string
 	switch cat {
+	case "":
+		// No category to apply
+		return "", nil
 	case gmail.CategoryPersonal:
 		smartl = SmartLabelPersonal
 	case gmail.CategorySocial:
@@ -3,6 +3,7 @@ package xml
 import (
 	"encoding/xml"
 	"io"
+	"sort"
 	"time"
 
 	cfgv2 "github.com/mbrt/gmailctl/pkg/config/v1alpha2"
@@ -12,6 +13,10 @@ import (
 // Exporter exports the given entries to the Gmail xml format.
 type Exporter interface {
 	// Export exports Gmail filters into the Gmail xml format.
+	//
+	// Removing labels is not supported by the format: the removals are
+	// dropped, together with the filters doing nothing else (see
+	// CheckUnsupported).
 	Export(author cfgv2.Author, filters filter.Filters, w io.Writer) error
 }
 
@@ -20,6 +25,13 @@ func DefaultExporter() Exporter {
 	return xmlExporter{now: defaultNow}
 }
 
+const (
+	// defaultTitle is the title of the filters without a name.
+	defaultTitle = "Mail Filter"
+	// filterTerm is the category of the entries representing filters.
+	filterTerm = "filter"
+)
+
 // nowFunc returns the current time
 type nowFunc func() time.Time
 
@@ -50,9 +62,9 @@ type xmlCategory struct {
 }
 
 type xmlProperty struct {
-	XMLName xml.Name `xml:"apps:property"`
-	Name    string   `xml:"name,attr"`
-	Value   string   `xml:"value,attr"`
+	XMLName xml.Name     `xml:"apps:property"`
+	Name    PropertyName `xml:"name,attr"`
+	Value   string       `xml:"value,attr"`
 }
 
 type xmlExporter struct {
@@ -97,23 +109,51 @@ func (x xmlExporter) toXML(author cfgv2.Author, filters filter.Filters) (xmlDoc,
 }
 
 func (x xmlExporter) entriesToXML(filters filter.Filters) ([]xmlEntry, error) {
-	res := make([]xmlEntry, len(filters))
-	for i, f := range filters {
+	var res []xmlEntry
+	for _, f := range filters {
+		if removesLabelOnly(f) {
+			// The entry would have no actions, which Gmail doesn't allow.
+			continue
+		}
 		props, err := x.propertiesToXML(f)
 		if err != nil {
 			return nil, err
 		}
+		title := defaultTitle
+		if f.Name != "" {
+			title = f.Name
+		}
 		xentry := xmlEntry{
-			Category:   xmlCategory{"filter"},
-			Title:      "Mail Filter",
+			Category:   xmlCategory{filterTerm},
+			Title:      title,
 			Content:    "",
 			Properties: props,
 		}
-		res[i] = xentry
+		res = append(res, orderProperties(xentry))
 	}
 	return res, nil
 }
 
+// orderProperties returns a copy of the entry with the properties sorted in
+// the same order used by Gmail, so that the output is easy to compare with
+// a Gmail export.
+func orderProperties(e xmlEntry) xmlEntry {
+	rank := func(p xmlProperty) int {
+		for i, name := range propertyOrder {
+			if p.Name == name {
+				return i
+			}
+		}
+		return len(propertyOrder)
+	}
+	props := append([]xmlProperty{}, e.Properties...)
+	sort.SliceStable(props, func(i, j int) bool {
+		return rank(props[i]) < rank(props[j])
+	})
+	e.Properties = props
+	return e
+}
+
 func (x xmlExporter) propertiesToXML(f filter.Filter) ([]xmlProperty, error) {
 	res := x.criteriaProperties(f.Criteria)
 	ap, err := x.actionProperties(f.Action)
@@ -129,7 +169,11 @@ func (x xmlExporter) criteriaProperties(c filter.Criteria) []xmlProperty {
 	res = x.appendStringProperty(res, PropertyFrom, c.From)
 	res = x.appendStringProperty(res, PropertyTo, c.To)
 	res = x.appendStringProperty(res, PropertySubject, c.Subject)
-	res = x.appendStringProperty(res, PropertyHas, c.Query)
+	// Gmail puts separate properties in AND, so the values required together
+	// (e.g. by 'hasAll') get a property each.
+	for _, t := range filter.QueryTerms(c.Query) {
+		res = x.appendStringProperty(res, PropertyHas, t)
+	}
 	return res
 }
 
@@ -143,19 +187,19 @@ func (x xmlExporter) actionProperties(a filter.Actions) ([]xmlProperty, error) {
 	res = x.appendBoolProperty(res, PropertyMarkNotSpam, a.MarkNotSpam)
 	res = x.appendBoolProperty(res, PropertyStar, a.Star)
 	res = x.appendStringProperty(res, PropertyApplyLabel, a.AddLabel)
+	res = x.appendStringProperty(res, PropertyForward, a.Forward)
 
-	if a.Category != "" {
-		cat, err := categoryToSmartLabel(a.Category)
-		if err != nil {
-			return nil, err
-		}
-		res = x.appendStringProperty(res, PropertyApplyCategory, cat)
+	// An empty category means no category action, and adds no property.
+	cat, err := categoryToSmartLabel(a.Category)
+	if err != nil {
+		return nil, err
 	}
+	res = x.appendStringProperty(res, PropertyApplyCategory, cat)
 
 	return res, nil
 }
 
-func (x xmlExporter) appendStringProperty(res []xmlProperty, name, value string) []xmlProperty {
+func (x xmlExporter) appendStringProperty(res []xmlProperty, name PropertyName, value string) []xmlProperty {
 	if value == "" {
 		return res
 	}
@@ -166,7 +210,7 @@ func (x xmlExporter) appendStringProperty(res []xmlProperty, name, value string)
 	return append(res, p)
 }
 
-func (x xmlExporter) appendBoolProperty(res []xmlProperty, name string, value bool) []xmlProperty {
+func (x xmlExporter) appendBoolProperty(res []xmlProperty, name PropertyName, value bool) []xmlProperty {
 	if !value {
 		return res
 	}
@@ -2,17 +2,25 @@ package xml
 
 import (
 	"bytes"
+	"flag"
+	"io/ioutil"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	cfgv1 "github.com/mbrt/gmailctl/pkg/config/v1alpha1"
 	cfgv2 "github.com/mbrt/gmailctl/pkg/config/v1alpha2"
 	"github.com/mbrt/gmailctl/pkg/filter"
 	"github.com/mbrt/gmailctl/pkg/gmail"
+	"github.com/mbrt/gmailctl/pkg/parser"
 )
 
+// update is useful to regenerate the golden files, whenever necessary.
+// Make sure the new version makes sense!!
+var update = flag.Bool("update", false, "update golden files")
+
 func testNow() time.Time {
 	// Make test deterministic, avoiding time.Now()
 	now, _ := time.Parse("2006/01/02 15:04", "2018/03/08 17:00")
@@ -86,8 +94,8 @@ func TestSomeEntries(t *testing.T) {
     <title>Mail Filter</title>
     <content></content>
     <apps:property name="hasTheWord" value="SPAM!!"></apps:property>
-    <apps:property name="shouldTrash" value="true"></apps:property>
     <apps:property name="label" value="spam"></apps:property>
+    <apps:property name="shouldTrash" value="true"></apps:property>
   </entry>
 </feed>
 `
@@ -136,13 +144,282 @@ func TestAllEntries(t *testing.T) {
     <apps:property name="to" value="me@gmail.com"></apps:property>
     <apps:property name="subject" value="subject"></apps:property>
     <apps:property name="hasTheWord" value="has words"></apps:property>
+    <apps:property name="label" value="MyLabel"></apps:property>
+    <apps:property name="smartLabelToApply" value="^smartlabel_promo"></apps:property>
     <apps:property name="shouldArchive" value="true"></apps:property>
+    <apps:property name="shouldMarkAsRead" value="true"></apps:property>
     <apps:property name="shouldTrash" value="true"></apps:property>
     <apps:property name="shouldAlwaysMarkAsImportant" value="true"></apps:property>
-    <apps:property name="shouldMarkAsRead" value="true"></apps:property>
-    <apps:property name="label" value="MyLabel"></apps:property>
-    <apps:property name="smartLabelToApply" value="^smartlabel_promo"></apps:property>
   </entry>
 </feed>`
 	assert.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(buf.String()))
 }
+
+func TestStar(t *testing.T) {
+	exporter := xmlExporter{now: testNow}
+
+	props, err := exporter.actionProperties(filter.Actions{Star: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []xmlProperty{{Name: PropertyStar, Value: "true"}}, props)
+
+	props, err = exporter.actionProperties(filter.Actions{Archive: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []xmlProperty{{Name: PropertyArchive, Value: "true"}}, props)
+}
+
+func TestNeverSpam(t *testing.T) {
+	exporter := xmlExporter{now: testNow}
+
+	props, err := exporter.actionProperties(filter.Actions{
+		MarkImportant: true,
+		MarkNotSpam:   true,
+	})
+	assert.Nil(t, err)
+	expected := []xmlProperty{
+		{Name: PropertyMarkImportant, Value: "true"},
+		{Name: PropertyMarkNotSpam, Value: "true"},
+	}
+	assert.Equal(t, expected, props)
+
+	props, err = exporter.actionProperties(filter.Actions{MarkImportant: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []xmlProperty{{Name: PropertyMarkImportant, Value: "true"}}, props)
+}
+
+func TestCategories(t *testing.T) {
+	// Moving emails to the primary tab (e.g. out of promotions) is done by
+	// applying the personal category.
+	tests := map[gmail.Category]string{
+		gmail.CategoryPersonal:   "^smartlabel_personal",
+		gmail.CategorySocial:     "^smartlabel_social",
+		gmail.CategoryUpdates:    "^smartlabel_notification",
+		gmail.CategoryForums:     "^smartlabel_group",
+		gmail.CategoryPromotions: "^smartlabel_promo",
+	}
+	for cat, expected := range tests {
+		got, err := categoryToSmartLabel(cat)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, got)
+
+		back, err := smartLabelToCategory(got)
+		assert.Nil(t, err)
+		assert.Equal(t, cat, back)
+	}
+}
+
+func TestEmptyCategory(t *testing.T) {
+	exporter := xmlExporter{now: testNow}
+
+	// An empty category means no category action at all
+	props, err := exporter.actionProperties(filter.Actions{
+		Archive:  true,
+		Category: "",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []xmlProperty{{Name: PropertyArchive, Value: "true"}}, props)
+
+	smartl, err := categoryToSmartLabel("")
+	assert.Nil(t, err)
+	assert.Equal(t, "", smartl)
+
+	// Unknown categories are still an error
+	_, err = categoryToSmartLabel("unknown")
+	assert.NotNil(t, err)
+}
+
+func TestCategoryAndImportant(t *testing.T) {
+	// Marking as important only the mails ending up in a category requires
+	// both actions on the same entry.
+	important := true
+	fs, err := filter.FromRules([]parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"boss@work.com"},
+			},
+			Actions: parser.Actions{
+				Category:      gmail.CategoryUpdates,
+				MarkImportant: &important,
+				Labels:        []string{"work"},
+			},
+		},
+	})
+	assert.Nil(t, err)
+
+	exporter := xmlExporter{now: testNow}
+	entries, err := exporter.entriesToXML(fs)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	props := map[PropertyName]string{}
+	for _, p := range entries[0].Properties {
+		props[p.Name] = p.Value
+	}
+	assert.Equal(t, "^smartlabel_notification", props[PropertyApplyCategory])
+	assert.Equal(t, "true", props[PropertyMarkImportant])
+	assert.Equal(t, "work", props[PropertyApplyLabel])
+}
+
+func TestPropertyNames(t *testing.T) {
+	for _, p := range allProperties {
+		assert.True(t, p.Valid())
+		parsed, err := ParsePropertyName(string(p))
+		assert.Nil(t, err)
+		assert.Equal(t, p, parsed)
+	}
+	assert.Equal(t, PropertyName("hasTheWord"), PropertyHas)
+
+	assert.False(t, PropertyName("foo").Valid())
+	_, err := ParsePropertyName("foo")
+	assert.EqualError(t, err, "unknown property 'foo'")
+}
+
+func TestUnknownCategory(t *testing.T) {
+	_, err := categoryToSmartLabel("foo")
+	assert.NotNil(t, err)
+	assert.Equal(t,
+		"unrecognized category 'foo' (possible values: personal, social, updates, forums, promotions)",
+		err.Error())
+}
+
+func TestGolden(t *testing.T) {
+	exporter := xmlExporter{now: testNow}
+	author := cfgv2.Author{Name: "Pippo Pluto", Email: "pippo@mail.com"}
+	filters := filter.Filters{
+		{
+			Name: "Golang nuts",
+			Action: filter.Actions{
+				Archive:     true,
+				MarkRead:    true,
+				Star:        true,
+				MarkNotSpam: true,
+				Category:    gmail.CategoryForums,
+				AddLabel:    "lists/golang",
+			},
+			Criteria: filter.Criteria{
+				Query: "list:golang-nuts@googlegroups.com",
+			},
+		},
+		{
+			Action: filter.Actions{
+				MarkNotImportant: true,
+				AddLabel:         "lists/golang",
+			},
+			Criteria: filter.Criteria{
+				From: "{a@golang.org b@golang.org}",
+			},
+		},
+		{
+			Action: filter.Actions{
+				Delete:  true,
+				Forward: "accountant@mail.com",
+			},
+			Criteria: filter.Criteria{
+				To:      "pippo+invoices@mail.com",
+				Subject: "invoice",
+			},
+		},
+		{
+			Action: filter.Actions{
+				MarkImportant: true,
+				Category:      gmail.CategoryPersonal,
+			},
+			Criteria: filter.Criteria{
+				From: "mom@mail.com",
+			},
+		},
+	}
+	buf := new(bytes.Buffer)
+	err := exporter.Export(author, filters, buf)
+	assert.Nil(t, err)
+
+	golden := "testdata/golden.xml"
+	if *update {
+		err = ioutil.WriteFile(golden, buf.Bytes(), 0644)
+		assert.Nil(t, err)
+		return
+	}
+	expected, err := ioutil.ReadFile(golden)
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), buf.String())
+}
+
+func TestOrderProperties(t *testing.T) {
+	entry := xmlEntry{
+		Properties: []xmlProperty{
+			{Name: PropertyForward, Value: "a@x.com"},
+			{Name: PropertyStar, Value: "true"},
+			{Name: PropertyApplyLabel, Value: "l1"},
+			{Name: PropertyArchive, Value: "true"},
+			{Name: PropertySubject, Value: "foo"},
+			{Name: PropertyFrom, Value: "b@x.com"},
+		},
+	}
+	expected := []xmlProperty{
+		{Name: PropertyFrom, Value: "b@x.com"},
+		{Name: PropertySubject, Value: "foo"},
+		{Name: PropertyApplyLabel, Value: "l1"},
+		{Name: PropertyArchive, Value: "true"},
+		{Name: PropertyStar, Value: "true"},
+		{Name: PropertyForward, Value: "a@x.com"},
+	}
+	got := orderProperties(entry)
+	assert.Equal(t, expected, got.Properties)
+	// The original entry is not modified
+	assert.Equal(t, PropertyForward, entry.Properties[0].Name)
+}
+
+func TestHasAll(t *testing.T) {
+	entries := hasAllEntries(t, cfgv1.MatchFilters{
+		HasAll: []string{"phrase a", "phrase b"},
+	})
+	// Every phrase has its own property, which Gmail puts in AND.
+	expected := []xmlProperty{
+		{Name: PropertyHas, Value: `"phrase a"`},
+		{Name: PropertyHas, Value: `"phrase b"`},
+		{Name: PropertyArchive, Value: "true"},
+	}
+	assert.Equal(t, expected, entries[0].Properties)
+}
+
+func TestHasAllWithHas(t *testing.T) {
+	entries := hasAllEntries(t, cfgv1.MatchFilters{
+		Has:    []string{"foo", "bar"},
+		HasAll: []string{"phrase a", "phrase b"},
+	})
+	// The 'has' values stay in OR, in a property of their own.
+	expected := []xmlProperty{
+		{Name: PropertyHas, Value: `"phrase a"`},
+		{Name: PropertyHas, Value: `"phrase b"`},
+		{Name: PropertyHas, Value: "{foo bar}"},
+		{Name: PropertyArchive, Value: "true"},
+	}
+	assert.Equal(t, expected, entries[0].Properties)
+}
+
+func hasAllEntries(t *testing.T, mf cfgv1.MatchFilters) []xmlEntry {
+	t.Helper()
+	v1cfg := cfgv1.Config{
+		Version: cfgv1.Version,
+		Rules: []cfgv1.Rule{
+			{
+				Filters: cfgv1.Filters{
+					CompositeFilters: cfgv1.CompositeFilters{
+						MatchFilters: mf,
+					},
+				},
+				Actions: cfgv1.Actions{Archive: true},
+			},
+		},
+	}
+	cfg, err := cfgv2.Import(v1cfg)
+	assert.Nil(t, err)
+	rules, err := parser.Parse(cfg)
+	assert.Nil(t, err)
+	fs, err := filter.FromRules(rules)
+	assert.Nil(t, err)
+
+	entries, err := xmlExporter{now: testNow}.entriesToXML(fs)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	return entries
+}
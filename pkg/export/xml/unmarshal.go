@@ -0,0 +1,204 @@
+package xml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+
+	"github.com/mbrt/gmailctl/pkg/filter"
+	"github.com/mbrt/gmailctl/pkg/gmail"
+)
+
+// Importer imports filters from the Gmail xml format.
+type Importer interface {
+	// Import imports Gmail filters from the Gmail xml format.
+	//
+	// If some filter is invalid, the import skips it and returns only the
+	// valid ones, but records and returns the error in the end.
+	Import(r io.Reader) (filter.Filters, error)
+}
+
+// DefaultImporter returns a default implementation of the Importer interface.
+func DefaultImporter() Importer {
+	return xmlImporter{}
+}
+
+type xmlImportDoc struct {
+	XMLName xml.Name         `xml:"feed"`
+	Entries []xmlImportEntry `xml:"entry"`
+}
+
+type xmlImportEntry struct {
+	Category   xmlCategory         `xml:"category"`
+	Title      string              `xml:"title"`
+	Properties []xmlImportProperty `xml:"http://schemas.google.com/apps/2006 property"`
+}
+
+type xmlImportProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type xmlImporter struct{}
+
+func (x xmlImporter) Import(r io.Reader) (filter.Filters, error) {
+	var doc xmlImportDoc
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, errors.Wrap(err, "invalid xml")
+	}
+
+	res := filter.Filters{}
+	var reserr error
+
+	for i, entry := range doc.Entries {
+		f, err := x.importEntry(entry)
+		if err != nil {
+			// We don't want to return here, but continue and skip the problematic filter
+			err = errors.Wrap(err, fmt.Sprintf("error importing filter #%d", i))
+			reserr = multierror.Append(reserr, err)
+		} else {
+			res = append(res, f)
+		}
+	}
+
+	return res, reserr
+}
+
+func (x xmlImporter) importEntry(entry xmlImportEntry) (filter.Filter, error) {
+	var res filter.Filter
+	var dontHave string
+	var size sizeProperties
+
+	if term := entry.Category.Term; term != "" && term != filterTerm {
+		return res, errors.Errorf("unsupported entry category '%s'", term)
+	}
+	// The name of the rule is kept in the title, when exported by us.
+	if title := strings.TrimSpace(entry.Title); title != defaultTitle {
+		res.Name = title
+	}
+
+	for _, p := range entry.Properties {
+		name, err := ParsePropertyName(p.Name)
+		if err != nil {
+			return res, err
+		}
+		switch name {
+		case PropertyFrom:
+			res.Criteria.From = p.Value
+		case PropertyTo:
+			res.Criteria.To = p.Value
+		case PropertySubject:
+			res.Criteria.Subject = p.Value
+		case PropertyHas:
+			res.Criteria.Query = joinWords(res.Criteria.Query, p.Value)
+		case PropertyDoesNotHave:
+			dontHave = p.Value
+		case PropertyHasAttachment:
+			res.Criteria.Query = joinWords(res.Criteria.Query, "has:attachment")
+		case PropertyArchive:
+			res.Action.Archive = true
+		case PropertyDelete:
+			res.Action.Delete = true
+		case PropertyMarkImportant:
+			res.Action.MarkImportant = true
+		case PropertyMarkNotImportant:
+			res.Action.MarkNotImportant = true
+		case PropertyMarkRead:
+			res.Action.MarkRead = true
+		case PropertyMarkNotSpam:
+			res.Action.MarkNotSpam = true
+		case PropertyStar:
+			res.Action.Star = true
+		case PropertyApplyLabel:
+			res.Action.AddLabel = p.Value
+		case PropertyForward:
+			res.Action.Forward = p.Value
+		case PropertyApplyCategory:
+			cat, err := smartLabelToCategory(p.Value)
+			if err != nil {
+				return res, err
+			}
+			res.Action.Category = cat
+		case PropertySize:
+			size.value = p.Value
+		case PropertySizeOperator:
+			size.operator = p.Value
+		case PropertySizeUnit:
+			size.unit = p.Value
+		case PropertyExcludeChats:
+			// Filters don't apply to chats anyways.
+		}
+	}
+
+	// Gmail exports the size operator and unit even when no size is specified.
+	if size.value != "" {
+		q, err := size.toQuery()
+		if err != nil {
+			return res, err
+		}
+		res.Criteria.Query = joinWords(res.Criteria.Query, q)
+	}
+	if dontHave != "" {
+		res.Criteria.Query = joinWords(res.Criteria.Query, fmt.Sprintf("-{%s}", dontHave))
+	}
+	if res.Criteria.Empty() {
+		return res, errors.New("no criteria specified")
+	}
+	if res.Action.Empty() {
+		return res, errors.New("no action specified")
+	}
+	return res, nil
+}
+
+type sizeProperties struct {
+	operator string
+	unit     string
+	value    string
+}
+
+func (s sizeProperties) toQuery() (string, error) {
+	var op, unit string
+	switch s.operator {
+	case "s_sl", "":
+		op = "larger"
+	case "s_ss":
+		op = "smaller"
+	default:
+		return "", errors.Errorf("unknown size operator '%s'", s.operator)
+	}
+	switch s.unit {
+	case "s_smb", "":
+		unit = "M"
+	case "s_skb":
+		unit = "K"
+	case "s_sb":
+		unit = ""
+	default:
+		return "", errors.Errorf("unknown size unit '%s'", s.unit)
+	}
+	return fmt.Sprintf("%s:%s%s", op, s.value, unit), nil
+}
+
+func joinWords(a, b string) string {
+	return strings.TrimSpace(fmt.Sprintf("%s %s", a, b))
+}
+
+func smartLabelToCategory(smartl string) (gmail.Category, error) {
+	switch strings.TrimPrefix(smartl, "^smartlabel_") {
+	case SmartLabelPersonal:
+		return gmail.CategoryPersonal, nil
+	case SmartLabelSocial:
+		return gmail.CategorySocial, nil
+	case SmartLabelNotification:
+		return gmail.CategoryUpdates, nil
+	case SmartLabelGroup:
+		return gmail.CategoryForums, nil
+	case SmartLabelPromo:
+		return gmail.CategoryPromotions, nil
+	}
+	return "", errors.Errorf("unrecognized smart label '%s'", smartl)
+}
@@ -0,0 +1,157 @@
+package xml
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	cfgv2 "github.com/mbrt/gmailctl/pkg/config/v1alpha2"
+	"github.com/mbrt/gmailctl/pkg/filter"
+	"github.com/mbrt/gmailctl/pkg/gmail"
+)
+
+const exportedXML = `<?xml version='1.0' encoding='UTF-8'?>
+<feed xmlns='http://www.w3.org/2005/Atom' xmlns:apps='http://schemas.google.com/apps/2006'>
+	<title>Mail Filters</title>
+	<id>tag:mail.google.com,2008:filters:1234,5678</id>
+	<updated>2019-01-18T20:07:26Z</updated>
+	<author>
+		<name>Pippo Pluto</name>
+		<email>pippo@mail.com</email>
+	</author>
+	<entry>
+		<category term='filter'></category>
+		<title>Mail Filter</title>
+		<id>tag:mail.google.com,2008:filter:1234</id>
+		<updated>2019-01-18T20:07:26Z</updated>
+		<content></content>
+		<apps:property name='from' value='{a@x.com b@x.com}'/>
+		<apps:property name='label' value='l1'/>
+		<apps:property name='shouldArchive' value='true'/>
+		<apps:property name='sizeOperator' value='s_sl'/>
+		<apps:property name='sizeUnit' value='s_smb'/>
+	</entry>
+	<entry>
+		<category term='filter'></category>
+		<title>Mail Filter</title>
+		<id>tag:mail.google.com,2008:filter:5678</id>
+		<updated>2019-01-18T20:07:26Z</updated>
+		<content></content>
+		<apps:property name='from' value='{a@x.com b@x.com}'/>
+		<apps:property name='label' value='l2'/>
+		<apps:property name='sizeOperator' value='s_sl'/>
+		<apps:property name='sizeUnit' value='s_smb'/>
+	</entry>
+	<entry>
+		<category term='filter'></category>
+		<title>Mail Filter</title>
+		<content></content>
+		<apps:property name='hasTheWord' value='list:foo'/>
+		<apps:property name='doesNotHaveTheWord' value='bar baz'/>
+		<apps:property name='smartLabelToApply' value='^smartlabel_group'/>
+		<apps:property name='shouldNeverSpam' value='true'/>
+	</entry>
+	<entry>
+		<category term='filter'></category>
+		<title>Mail Filter</title>
+		<content></content>
+		<apps:property name='hasAttachment' value='true'/>
+		<apps:property name='size' value='10'/>
+		<apps:property name='sizeOperator' value='s_ss'/>
+		<apps:property name='sizeUnit' value='s_skb'/>
+		<apps:property name='shouldStar' value='true'/>
+	</entry>
+</feed>
+`
+
+func TestImport(t *testing.T) {
+	got, err := DefaultImporter().Import(strings.NewReader(exportedXML))
+	assert.Nil(t, err)
+
+	expected := filter.Filters{
+		{
+			Criteria: filter.Criteria{From: "{a@x.com b@x.com}"},
+			Action:   filter.Actions{Archive: true, AddLabel: "l1"},
+		},
+		{
+			Criteria: filter.Criteria{From: "{a@x.com b@x.com}"},
+			Action:   filter.Actions{AddLabel: "l2"},
+		},
+		{
+			Criteria: filter.Criteria{Query: "list:foo -{bar baz}"},
+			Action: filter.Actions{
+				Category:    gmail.CategoryForums,
+				MarkNotSpam: true,
+			},
+		},
+		{
+			Criteria: filter.Criteria{Query: "has:attachment smaller:10K"},
+			Action:   filter.Actions{Star: true},
+		},
+	}
+	assert.Equal(t, expected, got)
+}
+
+func TestImportBad(t *testing.T) {
+	doc := `<?xml version='1.0' encoding='UTF-8'?>
+<feed xmlns='http://www.w3.org/2005/Atom' xmlns:apps='http://schemas.google.com/apps/2006'>
+	<entry>
+		<apps:property name='from' value='a@x.com'/>
+		<apps:property name='smartLabelToApply' value='^smartlabel_foo'/>
+	</entry>
+	<entry>
+		<apps:property name='from' value='b@x.com'/>
+		<apps:property name='shouldTrash' value='true'/>
+	</entry>
+</feed>`
+	got, err := DefaultImporter().Import(strings.NewReader(doc))
+	assert.NotNil(t, err)
+
+	// The valid filters are imported anyways.
+	expected := filter.Filters{
+		{
+			Criteria: filter.Criteria{From: "b@x.com"},
+			Action:   filter.Actions{Delete: true},
+		},
+	}
+	assert.Equal(t, expected, got)
+}
+
+func TestImportNameRoundTrip(t *testing.T) {
+	fs := filter.Filters{
+		{
+			Name:     "newsletters",
+			Criteria: filter.Criteria{From: "news@x.com"},
+			Action:   filter.Actions{Archive: true},
+		},
+		{
+			Criteria: filter.Criteria{From: "other@x.com"},
+			Action:   filter.Actions{Star: true},
+		},
+	}
+	buf := new(bytes.Buffer)
+	err := xmlExporter{now: testNow}.Export(cfgv2.Author{}, fs, buf)
+	assert.Nil(t, err)
+
+	got, err := DefaultImporter().Import(buf)
+	assert.Nil(t, err)
+	// Filters without a name get the default title, which is not imported
+	// back as a name.
+	assert.Equal(t, fs, got)
+}
+
+func TestImportUnsupportedCategory(t *testing.T) {
+	doc := `<?xml version='1.0' encoding='UTF-8'?>
+<feed xmlns='http://www.w3.org/2005/Atom' xmlns:apps='http://schemas.google.com/apps/2006'>
+	<entry>
+		<category term='contact'/>
+		<apps:property name='from' value='a@x.com'/>
+		<apps:property name='shouldStar' value='true'/>
+	</entry>
+</feed>`
+	got, err := DefaultImporter().Import(strings.NewReader(doc))
+	assert.NotNil(t, err)
+	assert.Empty(t, got)
+}
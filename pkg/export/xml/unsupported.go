@@ -0,0 +1,40 @@
+package xml
+
+import (
+	"fmt"
+
+	"github.com/mbrt/gmailctl/pkg/filter"
+)
+
+// CheckUnsupported returns warnings for the actions of the given filters
+// that can't be expressed in the XML format and are dropped by the export.
+// Filters only removing a label are dropped entirely.
+func CheckUnsupported(fs filter.Filters) []filter.Warning {
+	var res []filter.Warning
+	for i, f := range fs {
+		if removesLabelOnly(f) {
+			res = append(res, filter.Warning{
+				Message: fmt.Sprintf("filter #%d only removes label '%s', which is not supported in the XML format, and is skipped",
+					i, f.Action.RemoveLabel),
+			})
+			continue
+		}
+		if f.Action.RemoveLabel != "" {
+			res = append(res, filter.Warning{
+				Message: fmt.Sprintf("removing label '%s' is not supported in the XML format", f.Action.RemoveLabel),
+			})
+		}
+	}
+	return res
+}
+
+// removesLabelOnly returns true if removing a label is the only action of
+// the filter.
+func removesLabelOnly(f filter.Filter) bool {
+	a := f.Action
+	if a.RemoveLabel == "" {
+		return false
+	}
+	a.RemoveLabel = ""
+	return a.Empty()
+}
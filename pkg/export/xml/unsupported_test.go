@@ -0,0 +1,85 @@
+package xml
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	cfgv2 "github.com/mbrt/gmailctl/pkg/config/v1alpha2"
+	"github.com/mbrt/gmailctl/pkg/filter"
+	"github.com/mbrt/gmailctl/pkg/parser"
+)
+
+func TestRemoveLabelUnsupported(t *testing.T) {
+	fs := filter.Filters{
+		{
+			Criteria: filter.Criteria{From: "foo@bar.com"},
+			Action: filter.Actions{
+				AddLabel:    "done",
+				RemoveLabel: "todo",
+			},
+		},
+		{
+			Criteria: filter.Criteria{From: "baz@bar.com"},
+			Action:   filter.Actions{AddLabel: "other"},
+		},
+	}
+	expected := []filter.Warning{
+		{
+			Message: "removing label 'todo' is not supported in the XML format",
+		},
+	}
+	assert.Equal(t, expected, CheckUnsupported(fs))
+
+	// The export still succeeds, dropping the removal.
+	exporter := xmlExporter{now: testNow}
+	buf := new(bytes.Buffer)
+	err := exporter.Export(cfgv2.Author{}, fs, buf)
+	assert.Nil(t, err)
+	assert.NotContains(t, buf.String(), "todo")
+	assert.Contains(t, buf.String(), "done")
+}
+
+func TestRemoveLabelOnlyRoundTrip(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"a@x.com"},
+			},
+			// The second removal is split into a separate filter.
+			Actions: parser.Actions{
+				Labels:       []string{"done"},
+				RemoveLabels: []string{"todo", "later"},
+			},
+		},
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"b@x.com"},
+			},
+			Actions: parser.Actions{RemoveLabels: []string{"todo"}},
+		},
+	}
+	fs, err := filter.FromRules(rules)
+	assert.Nil(t, err)
+	assert.Len(t, fs, 3)
+	assert.Len(t, CheckUnsupported(fs), 3)
+
+	buf := new(bytes.Buffer)
+	err = xmlExporter{now: testNow}.Export(cfgv2.Author{}, fs, buf)
+	assert.Nil(t, err)
+
+	// Only the filters with other actions are exported, and all of them can
+	// be imported back.
+	got, err := DefaultImporter().Import(buf)
+	assert.Nil(t, err)
+	expected := filter.Filters{
+		{
+			Criteria: filter.Criteria{From: "a@x.com"},
+			Action:   filter.Actions{AddLabel: "done"},
+		},
+	}
+	assert.Equal(t, expected, got)
+}
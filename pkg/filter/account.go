@@ -0,0 +1,13 @@
+package filter
+
+import (
+	cfg "github.com/mbrt/gmailctl/pkg/config/v1alpha2"
+)
+
+// FromConfigForAccount translates the rules of the config applying to the
+// given account into Gmail filters.
+//
+// Rules without accounts apply to every account.
+func FromConfigForAccount(config cfg.Config, account string) (Filters, error) {
+	return FromConfig(config.ForAccount(account), GenerateOptions{})
+}
@@ -0,0 +1,80 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	cfg "github.com/mbrt/gmailctl/pkg/config/v1alpha2"
+)
+
+func TestFromConfigForAccount(t *testing.T) {
+	config := cfg.Config{
+		Rules: []cfg.Rule{
+			{
+				Filter:  cfg.FilterNode{From: "spam@x.com"},
+				Actions: cfg.Actions{Delete: true},
+			},
+			{
+				Accounts: []string{"me@work.com"},
+				Filter:   cfg.FilterNode{From: "boss@work.com"},
+				Actions:  cfg.Actions{Star: true},
+			},
+			{
+				Accounts: []string{"me@gmail.com", "me@yahoo.com"},
+				Filter:   cfg.FilterNode{From: "mom@gmail.com"},
+				Actions:  cfg.Actions{Labels: []string{"family"}},
+			},
+		},
+	}
+
+	got, err := FromConfigForAccount(config, "Me@Work.com")
+	assert.Nil(t, err)
+	expected := Filters{
+		{
+			Criteria: Criteria{From: "spam@x.com"},
+			Action:   Actions{Delete: true},
+		},
+		{
+			Criteria: Criteria{From: "boss@work.com"},
+			Action:   Actions{Star: true},
+		},
+	}
+	assert.Equal(t, expected, got)
+
+	got, err = FromConfigForAccount(config, "me@gmail.com")
+	assert.Nil(t, err)
+	expected = Filters{
+		{
+			Criteria: Criteria{From: "spam@x.com"},
+			Action:   Actions{Delete: true},
+		},
+		{
+			Criteria: Criteria{From: "mom@gmail.com"},
+			Action:   Actions{AddLabel: "family"},
+		},
+	}
+	assert.Equal(t, expected, got)
+}
+
+func TestFromConfigForAccountRuleError(t *testing.T) {
+	config := cfg.Config{
+		Rules: []cfg.Rule{
+			{
+				Accounts: []string{"me@gmail.com"},
+				Filter:   cfg.FilterNode{From: "mom@gmail.com"},
+				Actions:  cfg.Actions{Labels: []string{"family"}},
+			},
+			{
+				Filter:  cfg.FilterNode{From: "boss@work.com"},
+				Actions: cfg.Actions{Category: "foo"},
+			},
+		},
+	}
+
+	// Rules for other accounts still count in the index.
+	_, err := FromConfigForAccount(config, "me@work.com")
+	rerr, ok := err.(RuleError)
+	assert.True(t, ok)
+	assert.Equal(t, 1, rerr.Index)
+}
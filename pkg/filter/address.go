@@ -0,0 +1,72 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mbrt/gmailctl/pkg/gmail"
+	"github.com/mbrt/gmailctl/pkg/parser"
+)
+
+// addressRegexp matches full addresses ('local@domain') and bare domains
+// prefixed by '@'.
+var addressRegexp = regexp.MustCompile(`^[^@\s,;<>()]*@[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*$`)
+
+// CheckAddresses returns a warning for every address field of the rule
+// (from, to, cc, bcc) with values that don't look like email addresses or
+// domains, e.g. because of a typo.
+//
+// This is a warning and not an error, because Gmail allows partial matches
+// on these fields. Bare words like 'from:amazon' are valid, but they are
+// reported as well, as they are indistinguishable from a missing '@'.
+func CheckAddresses(rule parser.Rule) []Warning {
+	suspicious := map[parser.FunctionType][]string{}
+	collectSuspiciousAddresses(rule.Criteria, suspicious)
+
+	var res []Warning
+	for _, f := range []parser.FunctionType{
+		parser.FunctionFrom,
+		parser.FunctionTo,
+		parser.FunctionCc,
+		parser.FunctionBcc,
+	} {
+		values := suspicious[f]
+		if len(values) == 0 {
+			continue
+		}
+		res = append(res, Warning{
+			Message: fmt.Sprintf("'%s' values don't look like email addresses: '%s'",
+				f, strings.Join(values, "', '")),
+		})
+	}
+	return res
+}
+
+func collectSuspiciousAddresses(tree parser.CriteriaAST, res map[parser.FunctionType][]string) {
+	switch t := tree.(type) {
+	case *parser.Node:
+		for _, c := range t.Children {
+			collectSuspiciousAddresses(c, res)
+		}
+	case *parser.Leaf:
+		switch t.Function {
+		case parser.FunctionFrom, parser.FunctionTo, parser.FunctionCc, parser.FunctionBcc:
+		default:
+			return
+		}
+		for _, a := range t.Args {
+			if !looksLikeAddress(a) {
+				res[t.Function] = append(res[t.Function], a)
+			}
+		}
+	}
+}
+
+func looksLikeAddress(s string) bool {
+	// 'me' is a special value for the user's own address.
+	if s == "me" {
+		return true
+	}
+	return addressRegexp.MatchString(s) || gmail.IsDomain(s)
+}
@@ -0,0 +1,82 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mbrt/gmailctl/pkg/parser"
+)
+
+func TestCheckAddressesValid(t *testing.T) {
+	rule := parser.Rule{
+		Criteria: &parser.Node{
+			Operation: parser.OperationAnd,
+			Children: []parser.CriteriaAST{
+				&parser.Leaf{
+					Function: parser.FunctionFrom,
+					Grouping: parser.OperationOr,
+					Args:     []string{"boss@work.com", "@x.com", "y.com", "root@localhost", "me"},
+				},
+				&parser.Leaf{
+					// Not an address field
+					Function: parser.FunctionSubject,
+					Args:     []string{"hello world"},
+				},
+			},
+		},
+	}
+	assert.Empty(t, CheckAddresses(rule))
+}
+
+func TestCheckAddressesTypo(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Node{
+				Operation: parser.OperationAnd,
+				Children: []parser.CriteriaAST{
+					&parser.Leaf{
+						Function: parser.FunctionFrom,
+						Grouping: parser.OperationOr,
+						Args:     []string{"boss@@x", "ok@x.com", "a@x.com,"},
+					},
+					&parser.Leaf{
+						Function: parser.FunctionTo,
+						Args:     []string{"me@x.com"},
+					},
+				},
+			},
+			Actions: parser.Actions{Archive: true},
+		},
+	}
+	expected := []Warning{
+		{
+			Message:   "'from' values don't look like email addresses: 'boss@@x', 'a@x.com,'",
+			RuleIndex: 0,
+			HasRule:   true,
+		},
+	}
+
+	// The check is opt-in
+	_, warnings, err := FromRulesWithWarnings(rules, GenerateOptions{})
+	assert.Nil(t, err)
+	assert.Empty(t, warnings)
+
+	_, warnings, err = FromRulesWithWarnings(rules, GenerateOptions{CheckAddresses: true})
+	assert.Nil(t, err)
+	assert.Equal(t, expected, warnings)
+}
+
+func TestCheckAddressesBareWord(t *testing.T) {
+	rule := parser.Rule{
+		Criteria: &parser.Leaf{
+			Function: parser.FunctionTo,
+			Args:     []string{"amazon"},
+		},
+	}
+	// Valid for Gmail, but it could be a missing '@' as well.
+	expected := []Warning{
+		{Message: "'to' values don't look like email addresses: 'amazon'"},
+	}
+	assert.Equal(t, expected, CheckAddresses(rule))
+}
@@ -2,34 +2,375 @@ package filter
 
 import (
 	"fmt"
+	"net/mail"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
 
+	cfg "github.com/mbrt/gmailctl/pkg/config/v1alpha2"
+	"github.com/mbrt/gmailctl/pkg/gmail"
 	"github.com/mbrt/gmailctl/pkg/parser"
 )
 
+// GenerateOptions contains the options for the generation of filters.
+type GenerateOptions struct {
+	// SortOr sorts alphabetically the values grouped in an OR, so that
+	// equivalent rules produce byte-identical filters, regardless of the
+	// order in which the values were listed.
+	SortOr bool
+	// SortFilters sorts the resulting filters by their contents, so that
+	// reordering the rules doesn't change the output.
+	SortFilters bool
+	// StripDestructive removes the actions that can't be easily reverted,
+	// useful to test new rules safely. See StripDestructive.
+	StripDestructive bool
+	// OrStyle is the syntax used for the values grouped in an OR.
+	OrStyle OrStyle
+	// Dedupe removes the identical filters generated by different rules.
+	// See Dedupe.
+	Dedupe bool
+	// LabelPrefix is prepended to all the applied labels, to distinguish
+	// them from the ones managed by hand. See PrefixLabels.
+	LabelPrefix string
+	// CheckAddresses warns about from, to, cc and bcc values that don't
+	// look like email addresses. See CheckAddresses.
+	CheckAddresses bool
+	// Tags selects the rules to generate by their tags. All the rules are
+	// generated by default.
+	Tags TagSelector
+}
+
+// OrStyle is the syntax used to express an OR in Gmail queries.
+type OrStyle int
+
+// OR styles.
+const (
+	// OrBraces groups the values in braces, e.g. '{a b}'.
+	OrBraces OrStyle = iota
+	// OrKeyword separates the values with the OR keyword, e.g. '(a OR b)'.
+	OrKeyword
+)
+
 // FromRules translates rules into entries that map directly into Gmail filters.
+//
+// The values of the operators appear in the filters in the same order as they
+// are in the rules.
 func FromRules(rs []parser.Rule) (Filters, error) {
+	return FromRulesWithOptions(rs, GenerateOptions{})
+}
+
+// FromConfig parses the rules of the config and translates them into Gmail
+// filters, by using the given options.
+//
+// The zero value of the options preserves the order and the contents of the
+// rules, producing the same filters as FromRules.
+func FromConfig(config cfg.Config, opts GenerateOptions) (Filters, error) {
+	res, _, err := FromConfigWithWarnings(config, opts)
+	return res, err
+}
+
+// FromConfigWithWarnings parses the rules of the config and translates them
+// into Gmail filters, by using the given options.
+//
+// Non-fatal issues are returned as warnings. Both warnings and errors refer
+// to the rules by their position in the config, disabled rules included.
+func FromConfigWithWarnings(config cfg.Config, opts GenerateOptions) (Filters, []Warning, error) {
+	parsed, err := parser.ParseRules(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return FromParsedRules(parsed, opts)
+}
+
+// FromParsedRules translates the rules returned by parser.ParseRules into
+// Gmail filters, by using the given options.
+//
+// Like in FromConfigWithWarnings, warnings and errors refer to the rules by
+// their position in the config.
+func FromParsedRules(parsed []parser.Rule, opts GenerateOptions) (Filters, []Warning, error) {
+	// Disabled rules are left empty by ParseRules.
+	var rules []parser.Rule
+	var indexes []int
+	for i, r := range parsed {
+		if r.Criteria == nil {
+			continue
+		}
+		rules = append(rules, r)
+		indexes = append(indexes, i)
+	}
+	return fromRules(rules, indexes, opts)
+}
+
+// FromRulesWithOptions translates rules into entries that map directly into
+// Gmail filters, by using the given options.
+func FromRulesWithOptions(rs []parser.Rule, opts GenerateOptions) (Filters, error) {
+	res, _, err := FromRulesWithWarnings(rs, opts)
+	return res, err
+}
+
+// FromRulesWithWarnings translates rules into entries that map directly into
+// Gmail filters, by using the given options.
+//
+// Non-fatal issues, such as ignored actions, are returned as warnings.
+func FromRulesWithWarnings(rs []parser.Rule, opts GenerateOptions) (Filters, []Warning, error) {
+	return fromRules(rs, nil, opts)
+}
+
+// fromRules translates the rules into Gmail filters. The indexes are the
+// positions of the rules used in warnings and errors, or nil to use their
+// position in rs.
+func fromRules(rs []parser.Rule, indexes []int, opts GenerateOptions) (Filters, []Warning, error) {
 	res := Filters{}
-	for i, rule := range rs {
-		filters, err := FromRule(rule)
+	// ruleIndexes[k] is the rule generating res[k], needed by the linter.
+	var ruleIndexes []int
+	var warnings []Warning
+	for j, rule := range rs {
+		i := j
+		if indexes != nil {
+			i = indexes[j]
+		}
+		if !opts.Tags.Matches(rule.Tags) {
+			continue
+		}
+		// The checks are about deleted messages, which don't apply when
+		// destructive actions are stripped.
+		if !opts.StripDestructive {
+			for _, w := range CheckActions(rule.Actions) {
+				warnings = append(warnings, w.forRule(i))
+			}
+		}
+		if opts.StripDestructive {
+			for _, a := range destructiveActions(rule.Actions) {
+				warnings = append(warnings, Warning{
+					Message: fmt.Sprintf("'%s' is ignored because destructive actions are stripped", a),
+				}.forRule(i))
+			}
+		}
+
+		if opts.CheckAddresses {
+			for _, w := range CheckAddresses(rule) {
+				warnings = append(warnings, w.forRule(i))
+			}
+		}
+
+		filters, err := fromRuleWithOptions(rule, i, opts)
 		if err != nil {
-			return res, errors.Wrap(err, fmt.Sprintf("error generating rule #%d", i))
+			return res, warnings, err
+		}
+		if opts.StripDestructive && len(filters) == 0 {
+			warnings = append(warnings, Warning{
+				Message: "the rule is dropped, because it has only destructive actions",
+			}.forRule(i))
+		}
+		for range filters {
+			ruleIndexes = append(ruleIndexes, i)
 		}
 		res = append(res, filters...)
 	}
-	return res, nil
+	overlaps := LintOverlaps(res, ruleIndexes)
+	if opts.Dedupe {
+		res = Dedupe(res)
+	}
+	if opts.SortFilters {
+		SortFilters(res)
+	}
+	warnings = append(warnings, CheckLimits(res, MaxFilters)...)
+	warnings = append(warnings, overlaps...)
+	return res, warnings, nil
+}
+
+// fromRuleWithOptions translates a single rule into Gmail filters, by
+// applying the options not requiring the other filters.
+func fromRuleWithOptions(rule parser.Rule, index int, opts GenerateOptions) (Filters, error) {
+	if opts.SortOr {
+		rule.Criteria = sortOrArgs(rule.Criteria)
+	}
+	filters, err := fromRule(rule, opts.OrStyle)
+	if err != nil {
+		return nil, RuleError{
+			Index:   index,
+			Summary: ruleSummary(rule),
+			Err:     err,
+		}
+	}
+	if opts.StripDestructive {
+		filters = StripDestructive(filters)
+	}
+	if opts.LabelPrefix != "" {
+		filters = PrefixLabels(filters, opts.LabelPrefix)
+	}
+	return filters, nil
+}
+
+// FromRulesStream translates rules into Gmail filters, calling emit for
+// each of them as soon as it's generated, instead of collecting all of them
+// in memory.
+//
+// Generation stops at the first error, including the ones returned by emit.
+// Sorting the filters requires all of them, so SortFilters is not supported,
+// while warnings (e.g. CheckAddresses) are not reported.
+func FromRulesStream(rs []parser.Rule, opts GenerateOptions, emit func(Filter) error) error {
+	if opts.SortFilters {
+		return errors.New("sorting the filters is not supported when streaming")
+	}
+	seen := map[string]struct{}{}
+
+	for i, rule := range rs {
+		// Disabled rules are left empty by parser.ParseRules.
+		if rule.Criteria == nil || !opts.Tags.Matches(rule.Tags) {
+			continue
+		}
+		filters, err := fromRuleWithOptions(rule, i, opts)
+		if err != nil {
+			return err
+		}
+		for _, f := range filters {
+			if opts.Dedupe {
+				fp := Fingerprint(f)
+				if _, ok := seen[fp]; ok {
+					continue
+				}
+				seen[fp] = struct{}{}
+			}
+			if err := emit(f); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// destructiveActions returns the names of the actions removed by
+// StripDestructive present in the given ones.
+func destructiveActions(a parser.Actions) []string {
+	var res []string
+	if a.Delete {
+		res = append(res, "delete")
+	}
+	if a.Archive {
+		res = append(res, "archive")
+	}
+	if a.MarkRead {
+		res = append(res, "markRead")
+	}
+	if a.Forward != "" {
+		res = append(res, "forward")
+	}
+	return res
+}
+
+// StripDestructive returns a copy of the filters without the actions that
+// can't be easily reverted: delete, archive, mark as read and forward.
+//
+// Filters left without actions are removed, and FromRulesWithWarnings
+// reports the rules dropped this way. Applying the result is useful to check
+// that the rules match the expected emails, before enabling the actual
+// actions.
+func StripDestructive(fs Filters) Filters {
+	res := Filters{}
+	for _, f := range fs {
+		f.Action.Delete = false
+		f.Action.Archive = false
+		f.Action.MarkRead = false
+		f.Action.Forward = ""
+		if f.Action.Empty() {
+			continue
+		}
+		res = append(res, f)
+	}
+	return res
+}
+
+// PrefixLabels returns a copy of the filters where the applied labels start
+// with the given prefix. Labels already starting with it are left as they
+// are, so that applying the prefix multiple times has no further effect.
+func PrefixLabels(fs Filters, prefix string) Filters {
+	res := make(Filters, len(fs))
+	for i, f := range fs {
+		if l := f.Action.AddLabel; l != "" && !strings.HasPrefix(l, prefix) {
+			f.Action.AddLabel = prefix + l
+		}
+		res[i] = f
+	}
+	return res
+}
+
+// SortFilters sorts the given filters by their criteria and actions.
+//
+// IDs and names are ignored, so filters with the same contents end up in the
+// same order, regardless of the order of the rules generating them.
+func SortFilters(fs Filters) {
+	key := func(f Filter) string {
+		return Filter{Criteria: f.Criteria, Action: f.Action}.String()
+	}
+	sort.SliceStable(fs, func(i, j int) bool {
+		return key(fs[i]) < key(fs[j])
+	})
+}
+
+// RuleError is returned when a rule cannot be translated into filters.
+type RuleError struct {
+	// Index is the position of the rule in the config.
+	Index int
+	// Summary is a short description of the rule (e.g. 'from: a@b.com'),
+	// useful to locate it in the config.
+	Summary string
+	// Err is the actual error.
+	Err error
+}
+
+func (e RuleError) Error() string {
+	if e.Summary == "" {
+		return fmt.Sprintf("error generating rule #%d: %v", e.Index, e.Err)
+	}
+	return fmt.Sprintf("error generating rule #%d (%s): %v", e.Index, e.Summary, e.Err)
+}
+
+// Cause returns the underlying error.
+func (e RuleError) Cause() error {
+	return e.Err
+}
+
+// ruleSummary describes a rule by its first operator.
+func ruleSummary(rule parser.Rule) string {
+	tree := rule.Criteria
+	for tree != nil {
+		switch t := tree.(type) {
+		case *parser.Leaf:
+			if len(t.Args) == 0 {
+				return ""
+			}
+			return fmt.Sprintf("%v: %s", t.Function, t.Args[0])
+		case *parser.Node:
+			if len(t.Children) == 0 {
+				return ""
+			}
+			tree = t.Children[0]
+		default:
+			return ""
+		}
+	}
+	return ""
 }
 
 // FromRule translates a rule into entries that map directly into Gmail filters.
 func FromRule(rule parser.Rule) ([]Filter, error) {
+	return fromRule(rule, OrBraces)
+}
+
+func fromRule(rule parser.Rule, style OrStyle) ([]Filter, error) {
 	var crits []Criteria
 	for _, c := range splitRootOr(rule.Criteria) {
-		criteria, err := GenerateCriteria(c)
+		criteria, err := generateCriteria(c, style)
 		if err != nil {
 			return nil, errors.Wrap(err, "error generating criteria")
 		}
+		if criteria.Empty() {
+			// A filter without criteria would match every email.
+			return nil, errors.New("empty criteria")
+		}
 		crits = append(crits, criteria)
 	}
 
@@ -38,40 +379,67 @@ func FromRule(rule parser.Rule) ([]Filter, error) {
 		return nil, errors.Wrap(err, "error generating actions")
 	}
 
-	return combineCriteriaWithActions(crits, actions), nil
+	res := combineCriteriaWithActions(crits, actions)
+	for i := range res {
+		res[i].Name = rule.Name
+	}
+	return res, nil
+}
+
+// FilterCount returns the number of Gmail filters the given rule translates
+// into.
+//
+// A rule can produce multiple filters, for example when it applies multiple
+// labels, or when its criteria is a top-level 'or'.
+func FilterCount(rule parser.Rule) (int, error) {
+	fs, err := FromRule(rule)
+	return len(fs), err
 }
 
 // GenerateCriteria translates a rule criteria into an entry that maps
 // directly into Gmail filters.
 func GenerateCriteria(crit parser.CriteriaAST) (Criteria, error) {
+	return generateCriteria(crit, OrBraces)
+}
+
+// GenerateCriteriaWithOptions is like GenerateCriteria, but it formats the
+// criteria by using the given options (i.e. SortOr and OrStyle).
+func GenerateCriteriaWithOptions(crit parser.CriteriaAST, opts GenerateOptions) (Criteria, error) {
+	if opts.SortOr {
+		crit = sortOrArgs(crit)
+	}
+	return generateCriteria(crit, opts.OrStyle)
+}
+
+func generateCriteria(crit parser.CriteriaAST, style OrStyle) (Criteria, error) {
 	if node, ok := crit.(*parser.Node); ok {
-		return generateNode(node)
+		return generateNode(node, style)
 	}
 	if leaf, ok := crit.(*parser.Leaf); ok {
-		return generateLeaf(leaf)
+		return generateLeaf(leaf, style)
 	}
 	return Criteria{}, errors.New("found unknown criteria node")
 }
 
-func generateNode(node *parser.Node) (Criteria, error) {
+func generateNode(node *parser.Node, style OrStyle) (Criteria, error) {
 	switch node.Operation {
 	case parser.OperationOr:
-		query := ""
+		var parts []string
 		for _, child := range node.Children {
-			cq, err := generateCriteriaAsString(child)
+			cq, err := generateCriteriaAsString(child, style)
 			if err != nil {
 				return Criteria{}, err
 			}
-			query = joinQueries(query, cq)
+			parts = append(parts, cq)
 		}
 		return Criteria{
-			Query: fmt.Sprintf("{%s}", query),
+			Query: joinOr(parts, style),
 		}, nil
 
 	case parser.OperationAnd:
 		res := Criteria{}
 		for _, child := range node.Children {
-			crit, err := GenerateCriteria(child)
+			crit, err := generateCriteria(child, style)
 			if err != nil {
 				return res, err
 			}
@@ -84,7 +452,7 @@ func generateNode(node *parser.Node) (Criteria, error) {
 		if ln := len(node.Children); ln != 1 {
 			return Criteria{}, errors.Errorf("after 'not' got %d children, expected 1", ln)
 		}
-		cq, err := generateCriteriaAsString(node.Children[0])
+		cq, err := generateCriteriaAsString(node.Children[0], style)
 		return Criteria{
 			Query: fmt.Sprintf("-%s", cq),
 		}, err
@@ -93,14 +461,13 @@ func generateNode(node *parser.Node) (Criteria, error) {
 	return Criteria{}, errors.Errorf("unknown node operation %d", node.Operation)
 }
 
-func generateLeaf(leaf *parser.Leaf) (Criteria, error) {
-	needEscape := leaf.Function != parser.FunctionQuery
-	query := joinStrings(needEscape, leaf.Args...)
-	if len(leaf.Args) > 1 {
-		var err error
-		if query, err = groupWithOperation(query, leaf.Grouping); err != nil {
-			return Criteria{}, err
-		}
+func generateLeaf(leaf *parser.Leaf, style OrStyle) (Criteria, error) {
+	if err := validateArgs(leaf); err != nil {
+		return Criteria{}, err
+	}
+	query, err := leafArgs(leaf, style)
+	if err != nil {
+		return Criteria{}, err
 	}
 
 	switch leaf.Function {
@@ -116,13 +483,13 @@ func generateLeaf(leaf *parser.Leaf) (Criteria, error) {
 		return Criteria{
 			Subject: query,
 		}, nil
-	case parser.FunctionCc:
+	case parser.FunctionCc, parser.FunctionBcc, parser.FunctionList, parser.FunctionDeliveredTo,
+		parser.FunctionLarger, parser.FunctionSmaller, parser.FunctionFilename,
+		parser.FunctionIs, parser.FunctionIn, parser.FunctionOlderThan, parser.FunctionNewerThan,
+		parser.FunctionLabel, parser.FunctionMessageID:
+		// These operators don't have a dedicated field in Gmail filters
 		return Criteria{
-			Query: fmt.Sprintf("cc:%s", query),
-		}, nil
-	case parser.FunctionList:
-		return Criteria{
-			Query: fmt.Sprintf("list:%s", query),
+			Query: fmt.Sprintf("%v:%s", leaf.Function, query),
 		}, nil
 	case parser.FunctionHas, parser.FunctionQuery:
 		return Criteria{
@@ -133,36 +500,86 @@ func generateLeaf(leaf *parser.Leaf) (Criteria, error) {
 	}
 }
 
-func generateCriteriaAsString(crit parser.CriteriaAST) (string, error) {
+// sizeRegexp matches sizes in the Gmail format: bytes, or kilo and mega
+// bytes with the 'K' and 'M' suffixes (e.g. '10M').
+var sizeRegexp = regexp.MustCompile(`^[0-9]+[kKmM]?$`)
+
+// ageRegexp matches time periods in the Gmail format: a number of days,
+// months or years (e.g. '30d').
+var ageRegexp = regexp.MustCompile(`^[0-9]+[dmy]$`)
+
+// stateValues contains the values accepted by the state operators.
+var stateValues = map[parser.FunctionType][]string{
+	parser.FunctionIs: {"important", "muted", "read", "snoozed", "starred", "unread"},
+	parser.FunctionIn: {"anywhere", "chats", "inbox", "sent", "snoozed", "spam", "trash"},
+}
+
+func validateArgs(leaf *parser.Leaf) error {
+	switch leaf.Function {
+	case parser.FunctionOlderThan, parser.FunctionNewerThan:
+		for _, a := range leaf.Args {
+			if !ageRegexp.MatchString(a) {
+				return errors.Errorf("invalid time period '%s' for '%v' (expected e.g. 30d, 6m, 1y)",
+					a, leaf.Function)
+			}
+		}
+	case parser.FunctionIs, parser.FunctionIn:
+		valid := stateValues[leaf.Function]
+		for _, a := range leaf.Args {
+			if !containsString(valid, a) {
+				return errors.Errorf("unknown value '%s' for '%v' (possible values: %s)",
+					a, leaf.Function, strings.Join(valid, ", "))
+			}
+		}
+	case parser.FunctionLarger, parser.FunctionSmaller:
+		for _, a := range leaf.Args {
+			if !sizeRegexp.MatchString(a) {
+				return errors.Errorf("invalid size '%s' for '%v' (expected e.g. 1000, 100K, 10M)",
+					a, leaf.Function)
+			}
+		}
+	}
+	return nil
+}
+
+func containsString(a []string, s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func generateCriteriaAsString(crit parser.CriteriaAST, style OrStyle) (string, error) {
 	if node, ok := crit.(*parser.Node); ok {
-		return generateNodeAsString(node)
+		return generateNodeAsString(node, style)
 	}
 	if leaf, ok := crit.(*parser.Leaf); ok {
-		return generateLeafAsString(leaf)
+		return generateLeafAsString(leaf, style)
 	}
 	return "", errors.New("found unknown criteria node")
 }
 
-func generateNodeAsString(node *parser.Node) (string, error) {
-	query := ""
+func generateNodeAsString(node *parser.Node, style OrStyle) (string, error) {
+	var parts []string
 	for _, child := range node.Children {
-		cq, err := generateCriteriaAsString(child)
+		cq, err := generateCriteriaAsString(child, style)
 		if err != nil {
 			return "", err
 		}
-		query = joinQueries(query, cq)
+		parts = append(parts, cq)
 	}
-	return groupWithOperation(query, node.Operation)
+	return groupWithOperation(parts, node.Operation, style)
 }
 
-func generateLeafAsString(leaf *parser.Leaf) (string, error) {
-	needEscape := leaf.Function != parser.FunctionQuery
-	query := joinStrings(needEscape, leaf.Args...)
-	if len(leaf.Args) > 1 {
-		var err error
-		if query, err = groupWithOperation(query, leaf.Grouping); err != nil {
-			return "", err
-		}
+func generateLeafAsString(leaf *parser.Leaf, style OrStyle) (string, error) {
+	if err := validateArgs(leaf); err != nil {
+		return "", err
+	}
+	query, err := leafArgs(leaf, style)
+	if err != nil {
+		return "", err
 	}
 
 	switch leaf.Function {
@@ -173,10 +590,37 @@ func generateLeafAsString(leaf *parser.Leaf) (string, error) {
 	}
 }
 
-func groupWithOperation(query string, op parser.OperationType) (string, error) {
+// leafArgs returns the arguments of the leaf, escaped and grouped if needed.
+func leafArgs(leaf *parser.Leaf, style OrStyle) (string, error) {
+	args := leaf.Args
+	if leaf.Function == parser.FunctionMessageID {
+		args = trimMessageIDs(args)
+	}
+	if leaf.Function != parser.FunctionQuery {
+		// Only free text values can contain Gmail operators
+		args = escapeStrings(leaf.Function == parser.FunctionHas, args...)
+	}
+	if len(args) > 1 {
+		return groupWithOperation(args, leaf.Grouping, style)
+	}
+	return strings.Join(args, " "), nil
+}
+
+// trimMessageIDs removes the angle brackets surrounding message IDs (e.g.
+// '<123@mail.com>'), which are not accepted by Gmail.
+func trimMessageIDs(ids []string) []string {
+	res := make([]string, len(ids))
+	for i, id := range ids {
+		res[i] = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(id), "<"), ">")
+	}
+	return res
+}
+
+func groupWithOperation(parts []string, op parser.OperationType, style OrStyle) (string, error) {
+	query := strings.Join(parts, " ")
 	switch op {
 	case parser.OperationOr:
-		return fmt.Sprintf("{%s}", query), nil
+		return joinOr(parts, style), nil
 
 	case parser.OperationAnd:
 		return fmt.Sprintf("(%s)", query), nil
@@ -188,6 +632,14 @@ func groupWithOperation(query string, op parser.OperationType) (string, error) {
 	}
 }
 
+// joinOr groups the given parts in an OR, with the given style.
+func joinOr(parts []string, style OrStyle) string {
+	if style == OrKeyword {
+		return fmt.Sprintf("(%s)", strings.Join(parts, " OR "))
+	}
+	return fmt.Sprintf("{%s}", strings.Join(parts, " "))
+}
+
 func joinCriteria(c1, c2 Criteria) Criteria {
 	return Criteria{
 		From:    joinQueries(c1.From, c2.From),
@@ -209,30 +661,67 @@ func joinQueries(f1, f2 string) string {
 	return fmt.Sprintf("%s %s", f1, f2)
 }
 
-func joinStrings(escape bool, a ...string) string {
-	if escape {
-		return joinEscaped(a...)
+func escapeStrings(allowOperators bool, a ...string) []string {
+	res := make([]string, len(a))
+	for i, s := range a {
+		res[i] = escape(s, allowOperators)
 	}
-	return strings.Join(a, " ")
+	return res
 }
 
-func joinEscaped(a ...string) string {
-	return strings.Join(escapeStrings(a...), " ")
+// gmailOperators contains the Gmail search operators that can legitimately
+// appear in a value, such as 'has:attachment'.
+var gmailOperators = []string{
+	"after", "bcc", "before", "category", "cc", "deliveredto", "filename",
+	"from", "has", "in", "is", "label", "larger", "list", "newer",
+	"newer_than", "older", "older_than", "rfc822msgid", "size", "smaller",
+	"subject", "to",
 }
 
-func escapeStrings(a ...string) []string {
-	res := make([]string, len(a))
-	for i, s := range a {
-		res[i] = escape(s)
+func escape(a string, allowOperators bool) string {
+	// Quoting prevents spaces, grouping characters and colons from being
+	// interpreted by Gmail; embedded quotes need to be escaped.
+	if strings.ContainsAny(a, " \t{}()\"") ||
+		(strings.Contains(a, ":") && !(allowOperators && isOperator(a))) {
+		return fmt.Sprintf(`"%s"`, strings.Replace(a, `"`, `\"`, -1))
 	}
-	return res
+	return a
+}
+
+// isOperator returns true if the value is a single Gmail operator with its
+// argument (e.g. 'is:unread'), optionally negated (e.g. '-in:chats').
+func isOperator(a string) bool {
+	parts := strings.SplitN(strings.TrimPrefix(a, "-"), ":", 2)
+	return parts[1] != "" && !strings.Contains(parts[1], ":") &&
+		containsString(gmailOperators, strings.ToLower(parts[0]))
 }
 
-func escape(a string) string {
-	if strings.ContainsAny(a, " \t{}()") {
-		return fmt.Sprintf(`"%s"`, a)
+// sortOrArgs returns a copy of the tree, where the arguments of the leaves
+// grouped with an OR are sorted.
+func sortOrArgs(tree parser.CriteriaAST) parser.CriteriaAST {
+	switch t := tree.(type) {
+	case *parser.Node:
+		children := make([]parser.CriteriaAST, len(t.Children))
+		for i, c := range t.Children {
+			children[i] = sortOrArgs(c)
+		}
+		return &parser.Node{
+			Operation: t.Operation,
+			Children:  children,
+		}
+	case *parser.Leaf:
+		args := append([]string{}, t.Args...)
+		if t.Grouping == parser.OperationOr {
+			sort.Strings(args)
+		}
+		return &parser.Leaf{
+			Function: t.Function,
+			Grouping: t.Grouping,
+			Args:     args,
+		}
+	default:
+		return tree
 	}
-	return a
 }
 
 func splitRootOr(tree parser.CriteriaAST) []parser.CriteriaAST {
@@ -254,6 +743,9 @@ func splitRootOr(tree parser.CriteriaAST) []parser.CriteriaAST {
 }
 
 func generateActions(actions parser.Actions) ([]Actions, error) {
+	if actions.Category != "" {
+		actions.Category = gmail.NormalizeCategory(actions.Category)
+	}
 	res := []Actions{
 		{
 			Archive:          actions.Archive,
@@ -264,30 +756,139 @@ func generateActions(actions parser.Actions) ([]Actions, error) {
 			Category:         actions.Category,
 			MarkNotSpam:      fromOptionalBool(actions.MarkSpam, false),
 			Star:             actions.Star,
+			Forward:          actions.Forward,
 		},
 	}
 
 	if fromOptionalBool(actions.MarkSpam, true) {
 		return nil, errors.New("Gmail filters don't allow to send messages to spam directly")
 	}
+	if actions.Category != "" {
+		if err := validateCategory(actions.Category); err != nil {
+			return nil, err
+		}
+	}
+	if actions.Forward != "" {
+		if err := validateAddress(actions.Forward); err != nil {
+			return nil, errors.Wrap(err, "invalid forward address")
+		}
+	}
+
+	for _, label := range actions.Labels {
+		if err := validateLabel(label); err != nil {
+			return nil, err
+		}
+	}
+	for _, label := range actions.RemoveLabels {
+		if err := validateLabel(label); err != nil {
+			return nil, err
+		}
+		if containsString(actions.Labels, label) {
+			return nil, errors.Errorf("label '%s' is both applied and removed", label)
+		}
+	}
 
-	if len(actions.Labels) == 0 {
+	if len(actions.Labels) == 0 && len(actions.RemoveLabels) == 0 {
 		return res, nil
 	}
 	// Since every action can contain a single lable only, we might need to
 	// produce multiple actions.
 	//
-	// The first label can stay in the first action
-	res[0].AddLabel = actions.Labels[0]
-
-	// The rest of the labels need a separate action
-	for _, label := range actions.Labels[1:] {
-		res = append(res, Actions{AddLabel: label})
+	// Note that this is a limitation of Gmail itself, not of the XML export:
+	// even if the API accepts a list of label IDs, only one user label per
+	// filter is allowed. For this reason we split independently of the
+	// backend. Labels to be removed are split in the same way, by pairing
+	// them with the labels to be applied.
+	//
+	// The first labels can stay in the first action
+	res[0].AddLabel = labelAt(actions.Labels, 0)
+	res[0].RemoveLabel = labelAt(actions.RemoveLabels, 0)
+
+	// The rest of the labels need a separate action. The other actions
+	// are shared, so that every filter is complete on its own. Forwarding
+	// is the exception, as we don't want to send the same email twice.
+	shared := res[0]
+	shared.Forward = ""
+	for i := 1; i < len(actions.Labels) || i < len(actions.RemoveLabels); i++ {
+		a := shared
+		a.AddLabel = labelAt(actions.Labels, i)
+		a.RemoveLabel = labelAt(actions.RemoveLabels, i)
+		res = append(res, a)
 	}
 
 	return res, nil
 }
 
+// labelAt returns the label at the given position, or an empty string if
+// there is none.
+func labelAt(labels []string, i int) string {
+	if i < len(labels) {
+		return labels[i]
+	}
+	return ""
+}
+
+// validateCategory returns an error if the given category is not supported
+// by Gmail.
+//
+// Gmail allows a single category per filter; the config format enforces that
+// already, so we only need to check the value.
+func validateCategory(cat gmail.Category) error {
+	possib := gmail.PossibleCategoryValues()
+	for _, c := range possib {
+		if string(cat) == c {
+			return nil
+		}
+	}
+	return errors.Errorf("unrecognized category '%s' (possible values: %s)",
+		cat, strings.Join(possib, ", "))
+}
+
+// maxLabelLength is the maximum length of a label name allowed by Gmail.
+const maxLabelLength = 225
+
+// reservedLabels are the names of the system labels, which cannot be
+// applied as user labels.
+var reservedLabels = []string{
+	"CHAT", "DRAFT", "IMPORTANT", "INBOX", "SENT", "SPAM", "STARRED", "TRASH", "UNREAD",
+}
+
+// validateLabel returns an error if the given label name would be refused
+// by Gmail.
+func validateLabel(label string) error {
+	if strings.TrimSpace(label) != label {
+		return errors.Errorf("invalid label '%s': leading or trailing whitespace", label)
+	}
+	if l := len([]rune(label)); l > maxLabelLength {
+		return errors.Errorf("invalid label '%s': too long (%d characters, max %d)",
+			label, l, maxLabelLength)
+	}
+	for _, part := range strings.Split(label, labelSeparator) {
+		if strings.TrimSpace(part) == "" {
+			return errors.Errorf("invalid label '%s': empty name", label)
+		}
+	}
+	for _, r := range reservedLabels {
+		if strings.EqualFold(label, r) {
+			return errors.Errorf("invalid label '%s': reserved system label", label)
+		}
+	}
+	return nil
+}
+
+// validateAddress returns an error if the given string is not a plain
+// email address (e.g. 'name@example.com').
+func validateAddress(a string) error {
+	addr, err := mail.ParseAddress(a)
+	if err != nil {
+		return err
+	}
+	if addr.Address != a {
+		return errors.Errorf("'%s' is not a plain email address", a)
+	}
+	return nil
+}
+
 // fromOptionalBool returns the value of the given option if present,
 // reversing its value if positive is false.
 func fromOptionalBool(opt *bool, positive bool) bool {
@@ -1,10 +1,15 @@
 package filter
 
 import (
+	"io/ioutil"
+	"strings"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
 
+	cfg "github.com/mbrt/gmailctl/pkg/config/v1alpha2"
 	"github.com/mbrt/gmailctl/pkg/gmail"
 	"github.com/mbrt/gmailctl/pkg/parser"
 )
@@ -191,6 +196,8 @@ func TestSplitActions(t *testing.T) {
 				From: "a",
 			},
 			Action: Actions{
+				Archive:  true,
+				MarkRead: true,
 				AddLabel: "l2",
 			},
 		},
@@ -199,6 +206,8 @@ func TestSplitActions(t *testing.T) {
 				From: "a",
 			},
 			Action: Actions{
+				Archive:  true,
+				MarkRead: true,
 				AddLabel: "l3",
 			},
 		},
@@ -246,3 +255,1812 @@ func TestActions(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, expected, got)
 }
+
+func TestNotQuoting(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Node{
+				Operation: parser.OperationAnd,
+				Children: []parser.CriteriaAST{
+					&parser.Leaf{
+						Function: parser.FunctionFrom,
+						Args:     []string{"ci@baz.com"},
+					},
+					&parser.Node{
+						Operation: parser.OperationNot,
+						Children: []parser.CriteriaAST{
+							&parser.Leaf{
+								Function: parser.FunctionTo,
+								Args:     []string{"foobar@baz.com"},
+							},
+						},
+					},
+					&parser.Node{
+						Operation: parser.OperationNot,
+						Children: []parser.CriteriaAST{
+							&parser.Leaf{
+								Function: parser.FunctionHas,
+								Args:     []string{"Build failed"},
+							},
+						},
+					},
+				},
+			},
+			Actions: parser.Actions{
+				Archive: true,
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				From:  "ci@baz.com",
+				Query: `-to:foobar@baz.com -"Build failed"`,
+			},
+			Action: Actions{
+				Archive: true,
+			},
+		},
+	}
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestCcBcc(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionCc,
+				Args:     []string{"a@x.com"},
+			},
+			Actions: parser.Actions{
+				Archive: true,
+			},
+		},
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionBcc,
+				Grouping: parser.OperationOr,
+				Args:     []string{"a@x.com", "b@y.com"},
+			},
+			Actions: parser.Actions{
+				Archive: true,
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				Query: "cc:a@x.com",
+			},
+			Action: Actions{
+				Archive: true,
+			},
+		},
+		{
+			Criteria: Criteria{
+				Query: "bcc:{a@x.com b@y.com}",
+			},
+			Action: Actions{
+				Archive: true,
+			},
+		},
+	}
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestList(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionList,
+				Args:     []string{"devel.example.com"},
+			},
+			Actions: parser.Actions{
+				Labels: []string{"devel"},
+			},
+		},
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionList,
+				Grouping: parser.OperationOr,
+				Args:     []string{"devel.example.com", "users.example.com"},
+			},
+			Actions: parser.Actions{
+				Archive: true,
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				Query: "list:devel.example.com",
+			},
+			Action: Actions{
+				AddLabel: "devel",
+			},
+		},
+		{
+			Criteria: Criteria{
+				Query: "list:{devel.example.com users.example.com}",
+			},
+			Action: Actions{
+				Archive: true,
+			},
+		},
+	}
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestForward(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"shop@x.com"},
+			},
+			Actions: parser.Actions{
+				Forward: "accountant@y.com",
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				From: "shop@x.com",
+			},
+			Action: Actions{
+				Forward: "accountant@y.com",
+			},
+		},
+	}
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestForwardInvalid(t *testing.T) {
+	for _, addr := range []string{"accountant", "Accountant <accountant@y.com>", "a@y.com, b@y.com"} {
+		rules := []parser.Rule{
+			{
+				Criteria: &parser.Leaf{
+					Function: parser.FunctionFrom,
+					Args:     []string{"shop@x.com"},
+				},
+				Actions: parser.Actions{
+					Forward: addr,
+				},
+			},
+		}
+		_, err := FromRules(rules)
+		assert.NotNil(t, err, addr)
+	}
+}
+
+func TestMarkNotImportant(t *testing.T) {
+	// markImportant is a single optional field, so asking for both
+	// "always" and "never" important is not expressible.
+	for _, important := range []bool{true, false} {
+		rules := []parser.Rule{
+			{
+				Criteria: &parser.Leaf{
+					Function: parser.FunctionFrom,
+					Args:     []string{"a"},
+				},
+				Actions: parser.Actions{
+					MarkImportant: boolptr(important),
+				},
+			},
+		}
+		expected := Filters{
+			{
+				Criteria: Criteria{
+					From: "a",
+				},
+				Action: Actions{
+					MarkImportant:    important,
+					MarkNotImportant: !important,
+				},
+			},
+		}
+		got, err := FromRules(rules)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, got)
+	}
+}
+
+func TestInvalidCategory(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"a"},
+			},
+			Actions: parser.Actions{
+				Archive: true,
+			},
+		},
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"b"},
+			},
+			Actions: parser.Actions{
+				Category: "spam",
+			},
+		},
+	}
+	_, err := FromRules(rules)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "rule #1")
+	assert.Contains(t, err.Error(), "'spam'")
+	assert.Contains(t, err.Error(), "personal, social, updates, forums, promotions")
+}
+
+func TestOrNode(t *testing.T) {
+	// A root 'or' is split into multiple filters, so we need to nest it
+	// to get a single one.
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Node{
+				Operation: parser.OperationAnd,
+				Children: []parser.CriteriaAST{
+					&parser.Leaf{
+						Function: parser.FunctionTo,
+						Args:     []string{"me@x.com"},
+					},
+					&parser.Node{
+						Operation: parser.OperationOr,
+						Children: []parser.CriteriaAST{
+							&parser.Leaf{
+								Function: parser.FunctionFrom,
+								Args:     []string{"a@x.com"},
+							},
+							&parser.Leaf{
+								Function: parser.FunctionSubject,
+								Args:     []string{"hello"},
+							},
+						},
+					},
+				},
+			},
+			Actions: parser.Actions{
+				Archive: true,
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				To:    "me@x.com",
+				Query: "{from:a@x.com subject:hello}",
+			},
+			Action: Actions{
+				Archive: true,
+			},
+		},
+	}
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestNestedAndOr(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			// and(list:l, or(from:a, and(to:b, subject:c), not(or(cc:d, cc:e))))
+			Criteria: &parser.Node{
+				Operation: parser.OperationAnd,
+				Children: []parser.CriteriaAST{
+					&parser.Leaf{
+						Function: parser.FunctionList,
+						Args:     []string{"l"},
+					},
+					&parser.Node{
+						Operation: parser.OperationOr,
+						Children: []parser.CriteriaAST{
+							&parser.Leaf{
+								Function: parser.FunctionFrom,
+								Args:     []string{"a"},
+							},
+							&parser.Node{
+								Operation: parser.OperationAnd,
+								Children: []parser.CriteriaAST{
+									&parser.Leaf{
+										Function: parser.FunctionTo,
+										Args:     []string{"b"},
+									},
+									&parser.Leaf{
+										Function: parser.FunctionSubject,
+										Args:     []string{"c"},
+									},
+								},
+							},
+							&parser.Node{
+								Operation: parser.OperationNot,
+								Children: []parser.CriteriaAST{
+									&parser.Leaf{
+										Function: parser.FunctionCc,
+										Grouping: parser.OperationOr,
+										Args:     []string{"d", "e"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Actions: parser.Actions{
+				Archive: true,
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				Query: "list:l {from:a (to:b subject:c) -cc:{d e}}",
+			},
+			Action: Actions{
+				Archive: true,
+			},
+		},
+	}
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestDeliveredTo(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionDeliveredTo,
+				Args:     []string{"me+shopping@gmail.com"},
+			},
+			Actions: parser.Actions{
+				Labels: []string{"shopping"},
+			},
+		},
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionDeliveredTo,
+				Grouping: parser.OperationOr,
+				Args: []string{
+					"me+a@gmail.com",
+					"me+b@gmail.com",
+					"me+c@gmail.com",
+				},
+			},
+			Actions: parser.Actions{
+				Archive: true,
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				Query: "deliveredto:me+shopping@gmail.com",
+			},
+			Action: Actions{
+				AddLabel: "shopping",
+			},
+		},
+		{
+			Criteria: Criteria{
+				Query: "deliveredto:{me+a@gmail.com me+b@gmail.com me+c@gmail.com}",
+			},
+			Action: Actions{
+				Archive: true,
+			},
+		},
+	}
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestSize(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Node{
+				Operation: parser.OperationAnd,
+				Children: []parser.CriteriaAST{
+					&parser.Leaf{
+						Function: parser.FunctionLarger,
+						Args:     []string{"10M"},
+					},
+					&parser.Leaf{
+						Function: parser.FunctionSmaller,
+						Args:     []string{"20000000"},
+					},
+				},
+			},
+			Actions: parser.Actions{
+				Archive: true,
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				Query: "larger:10M smaller:20000000",
+			},
+			Action: Actions{
+				Archive: true,
+			},
+		},
+	}
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestInvalidSize(t *testing.T) {
+	for _, size := range []string{"10MB", "M", "ten", "-1", "1.5M"} {
+		rules := []parser.Rule{
+			{
+				Criteria: &parser.Node{
+					Operation: parser.OperationNot,
+					Children: []parser.CriteriaAST{
+						&parser.Leaf{
+							Function: parser.FunctionLarger,
+							Args:     []string{size},
+						},
+					},
+				},
+				Actions: parser.Actions{
+					Archive: true,
+				},
+			},
+		}
+		_, err := FromRules(rules)
+		assert.NotNil(t, err, size)
+	}
+}
+
+func TestAttachments(t *testing.T) {
+	config := cfg.Config{
+		Rules: []cfg.Rule{
+			{
+				Filter: cfg.FilterNode{
+					And: []cfg.FilterNode{
+						{HasAttachment: true},
+						{
+							Or: []cfg.FilterNode{
+								{Filename: "pdf"},
+								{Filename: "my doc.pdf"},
+							},
+						},
+					},
+				},
+				Actions: cfg.Actions{
+					Labels: []string{"docs"},
+				},
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				Query: `has:attachment filename:{pdf "my doc.pdf"}`,
+			},
+			Action: Actions{
+				AddLabel: "docs",
+			},
+		},
+	}
+	rules, err := parser.Parse(config)
+	assert.Nil(t, err)
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestSortOr(t *testing.T) {
+	rule := func(from, to []string) parser.Rule {
+		return parser.Rule{
+			Criteria: &parser.Node{
+				Operation: parser.OperationAnd,
+				Children: []parser.CriteriaAST{
+					&parser.Leaf{
+						Function: parser.FunctionFrom,
+						Grouping: parser.OperationOr,
+						Args:     from,
+					},
+					&parser.Leaf{
+						Function: parser.FunctionTo,
+						Grouping: parser.OperationAnd,
+						Args:     to,
+					},
+				},
+			},
+			Actions: parser.Actions{
+				Archive: true,
+			},
+		}
+	}
+	rules := []parser.Rule{
+		rule([]string{"c@x.com", "a@x.com", "b@x.com"}, []string{"z@x.com", "y@x.com"}),
+		rule([]string{"b@x.com", "c@x.com", "a@x.com"}, []string{"z@x.com", "y@x.com"}),
+	}
+	original := []string{"c@x.com", "a@x.com", "b@x.com"}
+
+	got, err := FromRulesWithOptions(rules, GenerateOptions{SortOr: true})
+	assert.Nil(t, err)
+	expected := Filter{
+		Criteria: Criteria{
+			From: "{a@x.com b@x.com c@x.com}",
+			// AND groups are left untouched
+			To: "(z@x.com y@x.com)",
+		},
+		Action: Actions{
+			Archive: true,
+		},
+	}
+	assert.Equal(t, Filters{expected, expected}, got)
+	// The rules are not modified
+	assert.Equal(t, original, rules[0].Criteria.(*parser.Node).Children[0].(*parser.Leaf).Args)
+
+	// By default the order is preserved
+	got, err = FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, "{c@x.com a@x.com b@x.com}", got[0].Criteria.From)
+	assert.Equal(t, "{b@x.com c@x.com a@x.com}", got[1].Criteria.From)
+}
+
+func TestEscapeSpecialChars(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionSubject,
+				Grouping: parser.OperationOr,
+				Args:     []string{`He said "hi"`, "status {ok}", `"quoted"`},
+			},
+			Actions: parser.Actions{
+				Archive: true,
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				Subject: `{"He said \"hi\"" "status {ok}" "\"quoted\""}`,
+			},
+			Action: Actions{
+				Archive: true,
+			},
+		},
+	}
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestEscapeColons(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Node{
+				Operation: parser.OperationAnd,
+				Children: []parser.CriteriaAST{
+					&parser.Leaf{
+						Function: parser.FunctionSubject,
+						Grouping: parser.OperationOr,
+						// Operators are quoted outside of free text
+						Args: []string{"http://x", "Re:hello", "is:unread"},
+					},
+					&parser.Leaf{
+						Function: parser.FunctionHas,
+						Grouping: parser.OperationAnd,
+						// Legitimate operators are left untouched, even
+						// when negated
+						Args: []string{"is:unread", "foo:bar", "label:a:b", "-in:chats", "-is:unread"},
+					},
+				},
+			},
+			Actions: parser.Actions{
+				Archive: true,
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				Subject: `{"http://x" "Re:hello" "is:unread"}`,
+				Query:   `(is:unread "foo:bar" "label:a:b" -in:chats -is:unread)`,
+			},
+			Action: Actions{
+				Archive: true,
+			},
+		},
+	}
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestRuleError(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"a@x.com"},
+			},
+			Actions: parser.Actions{Archive: true},
+		},
+		{
+			Criteria: &parser.Node{
+				Operation: parser.OperationAnd,
+				Children: []parser.CriteriaAST{
+					&parser.Leaf{
+						Function: parser.FunctionFrom,
+						Grouping: parser.OperationOr,
+						Args:     []string{"boss@x.com", "other@x.com"},
+					},
+					&parser.Leaf{
+						Function: parser.FunctionSubject,
+						Args:     []string{"hello"},
+					},
+				},
+			},
+			Actions: parser.Actions{Category: "foo"},
+		},
+	}
+	_, err := FromRules(rules)
+	rerr, ok := err.(RuleError)
+	assert.True(t, ok)
+	assert.Equal(t, 1, rerr.Index)
+	assert.Equal(t, "from: boss@x.com", rerr.Summary)
+	assert.Contains(t, err.Error(), "error generating rule #1 (from: boss@x.com): ")
+	assert.Equal(t, errors.Cause(rerr.Err), errors.Cause(err))
+}
+
+func TestRuleErrorDisabledRules(t *testing.T) {
+	disabled := false
+	config := cfg.Config{
+		Version: cfg.Version,
+		Rules: []cfg.Rule{
+			{
+				Enabled: &disabled,
+				Filter:  cfg.FilterNode{From: "a@x.com"},
+				Actions: cfg.Actions{Archive: true},
+			},
+			{
+				Filter:  cfg.FilterNode{From: "b@x.com"},
+				Actions: cfg.Actions{Category: "foo"},
+			},
+		},
+	}
+	// The index refers to the position in the config, disabled rules
+	// included.
+	_, _, err := FromConfigWithWarnings(config, GenerateOptions{})
+	rerr, ok := err.(RuleError)
+	assert.True(t, ok)
+	assert.Equal(t, 1, rerr.Index)
+}
+
+func TestFromParsedRules(t *testing.T) {
+	disabled := false
+	config := cfg.Config{
+		Version: cfg.Version,
+		Rules: []cfg.Rule{
+			{
+				Enabled: &disabled,
+				Filter:  cfg.FilterNode{From: "a@x.com"},
+				Actions: cfg.Actions{Archive: true},
+			},
+			{
+				Filter:  cfg.FilterNode{From: "b@x.com"},
+				Actions: cfg.Actions{Delete: true, Star: true},
+			},
+		},
+	}
+	rules, err := parser.ParseRules(config)
+	assert.Nil(t, err)
+	assert.Nil(t, ValidateRules(rules))
+	assert.Empty(t, FindDuplicates(rules))
+
+	fs, warnings, err := FromParsedRules(rules, GenerateOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, fs, 1)
+	assert.Equal(t, "b@x.com", fs[0].Criteria.From)
+	// The warnings refer to the position in the config
+	assert.NotEmpty(t, warnings)
+	for _, w := range warnings {
+		assert.Equal(t, 1, w.RuleIndex)
+	}
+}
+
+func TestStateOperators(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Node{
+				Operation: parser.OperationAnd,
+				Children: []parser.CriteriaAST{
+					&parser.Leaf{
+						Function: parser.FunctionFrom,
+						Args:     []string{"a@x.com"},
+					},
+					&parser.Leaf{
+						Function: parser.FunctionIs,
+						Args:     []string{"unread"},
+					},
+					&parser.Leaf{
+						Function: parser.FunctionIn,
+						Args:     []string{"anywhere"},
+					},
+				},
+			},
+			Actions: parser.Actions{
+				Star: true,
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				From:  "a@x.com",
+				Query: "is:unread in:anywhere",
+			},
+			Action: Actions{
+				Star: true,
+			},
+		},
+	}
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestStateOperatorsInvalid(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionIs,
+				Args:     []string{"shiny"},
+			},
+			Actions: parser.Actions{
+				Star: true,
+			},
+		},
+	}
+	_, err := FromRules(rules)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unknown value 'shiny' for 'is'")
+}
+
+func TestQueryWithFrom(t *testing.T) {
+	config := cfg.Config{
+		Rules: []cfg.Rule{
+			{
+				Filter: cfg.FilterNode{
+					And: []cfg.FilterNode{
+						{Query: "dinner AROUND 5 {friday saturday}"},
+						{From: "friend@x.com"},
+						{List: "friends"},
+					},
+				},
+				Actions: cfg.Actions{
+					Star: true,
+				},
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				From:  "friend@x.com",
+				Query: "list:friends dinner AROUND 5 {friday saturday}",
+			},
+			Action: Actions{
+				Star: true,
+			},
+		},
+	}
+	rules, err := parser.Parse(config)
+	assert.Nil(t, err)
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestAge(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Node{
+				Operation: parser.OperationAnd,
+				Children: []parser.CriteriaAST{
+					&parser.Leaf{
+						Function: parser.FunctionOlderThan,
+						Args:     []string{"30d"},
+					},
+					&parser.Leaf{
+						Function: parser.FunctionNewerThan,
+						Args:     []string{"1y"},
+					},
+				},
+			},
+			Actions: parser.Actions{
+				Archive: true,
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				Query: "older_than:30d newer_than:1y",
+			},
+			Action: Actions{
+				Archive: true,
+			},
+		},
+	}
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestAgeInvalid(t *testing.T) {
+	for _, age := range []string{"30x", "30", "d", "1.5y"} {
+		rules := []parser.Rule{
+			{
+				Criteria: &parser.Leaf{
+					Function: parser.FunctionOlderThan,
+					Args:     []string{age},
+				},
+				Actions: parser.Actions{
+					Archive: true,
+				},
+			},
+		}
+		_, err := FromRules(rules)
+		assert.NotNil(t, err, age)
+	}
+}
+
+func TestMarkSpamNotAllowed(t *testing.T) {
+	// Gmail doesn't support sending messages to spam from a filter, so
+	// 'markSpam: true' is rejected, alone or together with other actions.
+	for _, actions := range []parser.Actions{
+		{MarkSpam: boolptr(true)},
+		{MarkSpam: boolptr(true), Delete: true},
+		{MarkSpam: boolptr(true), Labels: []string{"spammy"}},
+	} {
+		rules := []parser.Rule{
+			{
+				Criteria: &parser.Leaf{
+					Function: parser.FunctionFrom,
+					Args:     []string{"spammer@x.com"},
+				},
+				Actions: actions,
+			},
+		}
+		_, err := FromRules(rules)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "don't allow to send messages to spam")
+	}
+}
+
+func TestInvalidLabels(t *testing.T) {
+	tests := []struct {
+		label string
+		err   string
+	}{
+		{strings.Repeat("a", 226), "too long"},
+		{"Inbox", "reserved system label"},
+		{"SPAM", "reserved system label"},
+		{" work", "whitespace"},
+		{"work/", "empty name"},
+		{"", "empty name"},
+	}
+	for _, tc := range tests {
+		rules := []parser.Rule{
+			{
+				Criteria: &parser.Leaf{
+					Function: parser.FunctionFrom,
+					Args:     []string{"a@x.com"},
+				},
+				Actions: parser.Actions{
+					Labels: []string{"ok", tc.label},
+				},
+			},
+		}
+		_, err := FromRules(rules)
+		if assert.NotNil(t, err, tc.label) {
+			assert.Contains(t, err.Error(), tc.err)
+		}
+	}
+
+	// Nested and long labels within the limit are fine.
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"a@x.com"},
+			},
+			Actions: parser.Actions{
+				Labels: []string{"work/inbox", strings.Repeat("a", 225)},
+			},
+		},
+	}
+	_, err := FromRules(rules)
+	assert.Nil(t, err)
+}
+
+func TestFilterCount(t *testing.T) {
+	rule := parser.Rule{
+		Criteria: &parser.Leaf{
+			Function: parser.FunctionFrom,
+			Args:     []string{"a@x.com"},
+		},
+		Actions: parser.Actions{
+			Labels: []string{"label1"},
+		},
+	}
+	count, err := FilterCount(rule)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+
+	rule.Actions.Labels = []string{"label1", "label2", "label3"}
+	count, err = FilterCount(rule)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+
+	// A top-level 'or' multiplies the filters.
+	rule.Criteria = &parser.Node{
+		Operation: parser.OperationOr,
+		Children: []parser.CriteriaAST{
+			&parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"a@x.com"},
+			},
+			&parser.Leaf{
+				Function: parser.FunctionTo,
+				Args:     []string{"b@x.com"},
+			},
+		},
+	}
+	count, err = FilterCount(rule)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, count)
+}
+
+func TestSortFilters(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionTo,
+				Args:     []string{"me@x.com"},
+			},
+			Actions: parser.Actions{
+				Labels: []string{"b", "a"},
+			},
+		},
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"a@x.com"},
+			},
+			Actions: parser.Actions{
+				Archive: true,
+			},
+		},
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionSubject,
+				Args:     []string{"hello"},
+			},
+			Actions: parser.Actions{
+				Star: true,
+			},
+		},
+	}
+	reversed := []parser.Rule{rules[2], rules[1], rules[0]}
+
+	opts := GenerateOptions{SortFilters: true}
+	got1, err := FromRulesWithOptions(rules, opts)
+	assert.Nil(t, err)
+	got2, err := FromRulesWithOptions(reversed, opts)
+	assert.Nil(t, err)
+	assert.Equal(t, got1, got2)
+	assert.Len(t, got1, 4)
+
+	// Without the option the order follows the rules.
+	got2, err = FromRules(reversed)
+	assert.Nil(t, err)
+	assert.NotEqual(t, got1, got2)
+	assert.Equal(t, "hello", got2[0].Criteria.Subject)
+}
+
+func TestSubjectAndOr(t *testing.T) {
+	subjects := []cfg.FilterNode{
+		{Subject: "invoice"},
+		{Subject: "paid"},
+	}
+	config := cfg.Config{
+		Rules: []cfg.Rule{
+			{
+				Filter:  cfg.FilterNode{And: subjects},
+				Actions: cfg.Actions{Archive: true},
+			},
+			{
+				Filter: cfg.FilterNode{
+					And: []cfg.FilterNode{
+						{From: "shop@x.com"},
+						{Or: subjects},
+					},
+				},
+				Actions: cfg.Actions{Archive: true},
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				Subject: "(invoice paid)",
+			},
+			Action: Actions{
+				Archive: true,
+			},
+		},
+		{
+			Criteria: Criteria{
+				From:    "shop@x.com",
+				Subject: "{invoice paid}",
+			},
+			Action: Actions{
+				Archive: true,
+			},
+		},
+	}
+	rules, err := parser.Parse(config)
+	assert.Nil(t, err)
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestNotFromMe(t *testing.T) {
+	// Equivalent to the 'notFromMe' function of the jsonnet library.
+	config := cfg.Config{
+		Rules: []cfg.Rule{
+			{
+				Filter: cfg.FilterNode{
+					And: []cfg.FilterNode{
+						{List: "foobar@list.com"},
+						{Not: &cfg.FilterNode{From: "me"}},
+					},
+				},
+				Actions: cfg.Actions{Archive: true},
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				Query: "list:foobar@list.com -from:me",
+			},
+			Action: Actions{
+				Archive: true,
+			},
+		},
+	}
+	rules, err := parser.Parse(config)
+	assert.Nil(t, err)
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestDuplicatedValues(t *testing.T) {
+	config := cfg.Config{
+		Rules: []cfg.Rule{
+			{
+				Filter: cfg.FilterNode{
+					And: []cfg.FilterNode{
+						{
+							Or: []cfg.FilterNode{
+								{From: "b@x.com"},
+								{From: "a@x.com"},
+								{From: "b@x.com"},
+							},
+						},
+						{
+							Or: []cfg.FilterNode{
+								{To: "me@x.com"},
+								{To: "me@x.com"},
+							},
+						},
+					},
+				},
+				Actions: cfg.Actions{Archive: true},
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				From: "{b@x.com a@x.com}",
+				To:   "me@x.com",
+			},
+			Action: Actions{
+				Archive: true,
+			},
+		},
+	}
+	rules, err := parser.Parse(config)
+	assert.Nil(t, err)
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestCategoryAliases(t *testing.T) {
+	tests := map[gmail.Category]gmail.Category{
+		"Updates":       gmail.CategoryUpdates,
+		" updates ":     gmail.CategoryUpdates,
+		"notifications": gmail.CategoryUpdates,
+		"Forum":         gmail.CategoryForums,
+		"PROMO":         gmail.CategoryPromotions,
+		"primary":       gmail.CategoryPersonal,
+		"social":        gmail.CategorySocial,
+	}
+	for cat, expected := range tests {
+		fs, err := FromRule(parser.Rule{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"a@x.com"},
+			},
+			Actions: parser.Actions{Category: cat},
+		})
+		if assert.Nil(t, err, cat) {
+			assert.Equal(t, expected, fs[0].Action.Category)
+		}
+	}
+
+	_, err := FromRule(parser.Rule{
+		Criteria: &parser.Leaf{
+			Function: parser.FunctionFrom,
+			Args:     []string{"a@x.com"},
+		},
+		Actions: parser.Actions{Category: "Notes"},
+	})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "personal, social, updates, forums, promotions")
+}
+
+func TestSplitActionsCategory(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"a"},
+			},
+			Actions: parser.Actions{
+				Category: gmail.CategoryUpdates,
+				Labels:   []string{"l1", "l2"},
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				From: "a",
+			},
+			Action: Actions{
+				Category: gmail.CategoryUpdates,
+				AddLabel: "l1",
+			},
+		},
+		{
+			Criteria: Criteria{
+				From: "a",
+			},
+			Action: Actions{
+				Category: gmail.CategoryUpdates,
+				AddLabel: "l2",
+			},
+		},
+	}
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestSplitActionsForward(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"a"},
+			},
+			Actions: parser.Actions{
+				Archive: true,
+				Forward: "b@x.com",
+				Labels:  []string{"l1", "l2"},
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				From: "a",
+			},
+			Action: Actions{
+				Archive:  true,
+				Forward:  "b@x.com",
+				AddLabel: "l1",
+			},
+		},
+		{
+			Criteria: Criteria{
+				From: "a",
+			},
+			// Forwarding is not replicated
+			Action: Actions{
+				Archive:  true,
+				AddLabel: "l2",
+			},
+		},
+	}
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestRemoveLabels(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"a"},
+			},
+			Actions: parser.Actions{
+				MarkRead:     true,
+				Labels:       []string{"done"},
+				RemoveLabels: []string{"todo", "waiting"},
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				From: "a",
+			},
+			Action: Actions{
+				MarkRead:    true,
+				AddLabel:    "done",
+				RemoveLabel: "todo",
+			},
+		},
+		{
+			Criteria: Criteria{
+				From: "a",
+			},
+			Action: Actions{
+				MarkRead:    true,
+				RemoveLabel: "waiting",
+			},
+		},
+	}
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+
+	// The same label can't be both applied and removed
+	rules[0].Actions.RemoveLabels = []string{"done"}
+	_, err = FromRules(rules)
+	assert.NotNil(t, err)
+}
+
+func TestEmptyCriteria(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{},
+			},
+			Actions: parser.Actions{
+				Delete: true,
+			},
+		},
+	}
+	_, err := FromRules(rules)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "empty criteria")
+}
+
+func TestStripDestructive(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"a"},
+			},
+			Actions: parser.Actions{
+				Archive:  true,
+				MarkRead: true,
+				Star:     true,
+				Labels:   []string{"l1", "l2"},
+			},
+		},
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"spammer"},
+			},
+			Actions: parser.Actions{
+				Delete: true,
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				From: "a",
+			},
+			Action: Actions{
+				Star:     true,
+				AddLabel: "l1",
+			},
+		},
+		{
+			Criteria: Criteria{
+				From: "a",
+			},
+			Action: Actions{
+				Star:     true,
+				AddLabel: "l2",
+			},
+		},
+	}
+	got, warnings, err := FromRulesWithWarnings(rules, GenerateOptions{StripDestructive: true})
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+	// Rules left without actions are dropped, but not silently.
+	assert.Contains(t, warnings, Warning{
+		Message:   "the rule is dropped, because it has only destructive actions",
+		RuleIndex: 1,
+		HasRule:   true,
+	})
+}
+
+func TestNotLabeled(t *testing.T) {
+	config := cfg.Config{
+		Rules: []cfg.Rule{
+			{
+				Filter: cfg.FilterNode{
+					And: []cfg.FilterNode{
+						{From: "news@x.com"},
+						{Not: &cfg.FilterNode{Label: "Important"}},
+					},
+				},
+				Actions: cfg.Actions{Archive: true},
+			},
+			{
+				Filter: cfg.FilterNode{
+					Not: &cfg.FilterNode{
+						Or: []cfg.FilterNode{
+							{Label: "Important"},
+							{Label: "My Label"},
+						},
+					},
+				},
+				Actions: cfg.Actions{MarkRead: true},
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				From:  "news@x.com",
+				Query: "-label:Important",
+			},
+			Action: Actions{Archive: true},
+		},
+		{
+			Criteria: Criteria{
+				Query: `-label:{Important "My Label"}`,
+			},
+			Action: Actions{MarkRead: true},
+		},
+	}
+	rules, err := parser.Parse(config)
+	assert.Nil(t, err)
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestNegatedValue(t *testing.T) {
+	// Negating a single value is done by combining the positive matches
+	// with a 'not' node.
+	config := cfg.Config{
+		Rules: []cfg.Rule{
+			{
+				Filter: cfg.FilterNode{
+					And: []cfg.FilterNode{
+						{
+							Or: []cfg.FilterNode{
+								{From: "example.com"},
+								{From: "example.org"},
+							},
+						},
+						{Not: &cfg.FilterNode{From: "noreply@example.com"}},
+					},
+				},
+				Actions: cfg.Actions{Archive: true},
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				From:  "{example.com example.org}",
+				Query: "-from:noreply@example.com",
+			},
+			Action: Actions{Archive: true},
+		},
+	}
+	rules, err := parser.Parse(config)
+	assert.Nil(t, err)
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestOrStyle(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Node{
+				Operation: parser.OperationAnd,
+				Children: []parser.CriteriaAST{
+					&parser.Leaf{
+						Function: parser.FunctionFrom,
+						Grouping: parser.OperationOr,
+						Args:     []string{"a@x.com", "b@x.com"},
+					},
+					&parser.Node{
+						Operation: parser.OperationOr,
+						Children: []parser.CriteriaAST{
+							&parser.Leaf{
+								Function: parser.FunctionList,
+								Args:     []string{"l1"},
+							},
+							&parser.Leaf{
+								Function: parser.FunctionCc,
+								Args:     []string{"c@x.com"},
+							},
+						},
+					},
+				},
+			},
+			Actions: parser.Actions{Archive: true},
+		},
+	}
+
+	tests := []struct {
+		style    OrStyle
+		expected Criteria
+	}{
+		{
+			style: OrBraces,
+			expected: Criteria{
+				From:  "{a@x.com b@x.com}",
+				Query: "{list:l1 cc:c@x.com}",
+			},
+		},
+		{
+			style: OrKeyword,
+			expected: Criteria{
+				From:  "(a@x.com OR b@x.com)",
+				Query: "(list:l1 OR cc:c@x.com)",
+			},
+		},
+	}
+	for _, tc := range tests {
+		got, err := FromRulesWithOptions(rules, GenerateOptions{OrStyle: tc.style})
+		assert.Nil(t, err)
+		expected := Filters{
+			{
+				Criteria: tc.expected,
+				Action:   Actions{Archive: true},
+			},
+		}
+		assert.Equal(t, expected, got)
+	}
+}
+
+func TestMessageID(t *testing.T) {
+	config := cfg.Config{
+		Rules: []cfg.Rule{
+			{
+				Filter:  cfg.FilterNode{MessageID: "<123.abc@mail.gmail.com>"},
+				Actions: cfg.Actions{Star: true},
+			},
+			{
+				Filter: cfg.FilterNode{
+					Or: []cfg.FilterNode{
+						{MessageID: "<1@x.com>"},
+						{MessageID: "2@x.com"},
+					},
+				},
+				Actions: cfg.Actions{Star: true},
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{
+				Query: "rfc822msgid:123.abc@mail.gmail.com",
+			},
+			Action: Actions{Star: true},
+		},
+		{
+			Criteria: Criteria{
+				Query: "rfc822msgid:{1@x.com 2@x.com}",
+			},
+			Action: Actions{Star: true},
+		},
+	}
+	rules, err := parser.Parse(config)
+	assert.Nil(t, err)
+	got, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestIsImportant(t *testing.T) {
+	from := cfg.FilterNode{From: "a@x.com"}
+	tests := []struct {
+		filter   cfg.FilterNode
+		expected Criteria
+	}{
+		{
+			filter:   cfg.FilterNode{And: []cfg.FilterNode{from, {Is: "important"}}},
+			expected: Criteria{From: "a@x.com", Query: "is:important"},
+		},
+		{
+			filter:   cfg.FilterNode{And: []cfg.FilterNode{from, {Not: &cfg.FilterNode{Is: "important"}}}},
+			expected: Criteria{From: "a@x.com", Query: "-is:important"},
+		},
+		{
+			filter:   from,
+			expected: Criteria{From: "a@x.com"},
+		},
+	}
+	for _, tc := range tests {
+		config := cfg.Config{
+			Rules: []cfg.Rule{{Filter: tc.filter, Actions: cfg.Actions{Star: true}}},
+		}
+		rules, err := parser.Parse(config)
+		assert.Nil(t, err)
+		got, err := FromRules(rules)
+		assert.Nil(t, err)
+		expected := Filters{
+			{Criteria: tc.expected, Action: Actions{Star: true}},
+		}
+		assert.Equal(t, expected, got)
+	}
+}
+
+func TestFromRulesStream(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"a@x.com"},
+			},
+			Actions: parser.Actions{Labels: []string{"l1", "l2"}},
+		},
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"b@x.com"},
+			},
+			Actions: parser.Actions{Archive: true},
+		},
+	}
+
+	var got Filters
+	err := FromRulesStream(rules, GenerateOptions{}, func(f Filter) error {
+		got = append(got, f)
+		return nil
+	})
+	assert.Nil(t, err)
+	expected, err := FromRules(rules)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+	assert.Len(t, got, 3)
+}
+
+func TestFromRulesStreamOptions(t *testing.T) {
+	rule := parser.Rule{
+		Criteria: &parser.Leaf{
+			Function: parser.FunctionFrom,
+			Grouping: parser.OperationOr,
+			Args:     []string{"b@x.com", "a@x.com"},
+		},
+		Actions: parser.Actions{Archive: true, Labels: []string{"l1"}},
+	}
+	rules := []parser.Rule{rule, rule}
+	opts := GenerateOptions{
+		SortOr:           true,
+		StripDestructive: true,
+		Dedupe:           true,
+		LabelPrefix:      "auto/",
+	}
+
+	var got Filters
+	err := FromRulesStream(rules, opts, func(f Filter) error {
+		got = append(got, f)
+		return nil
+	})
+	assert.Nil(t, err)
+	expected, err := FromRulesWithOptions(rules, opts)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+	assert.Len(t, got, 1)
+
+	err = FromRulesStream(rules, GenerateOptions{SortFilters: true}, func(f Filter) error {
+		return nil
+	})
+	assert.NotNil(t, err)
+}
+
+func TestFromRulesStreamDisabled(t *testing.T) {
+	disabled := false
+	config := cfg.Config{
+		Version: cfg.Version,
+		Rules: []cfg.Rule{
+			{
+				Enabled: &disabled,
+				Filter:  cfg.FilterNode{From: "a@x.com"},
+				Actions: cfg.Actions{Archive: true},
+			},
+			{
+				Filter:  cfg.FilterNode{From: "b@x.com"},
+				Actions: cfg.Actions{Star: true},
+			},
+		},
+	}
+	rules, err := parser.ParseRules(config)
+	assert.Nil(t, err)
+
+	var got Filters
+	err = FromRulesStream(rules, GenerateOptions{}, func(f Filter) error {
+		got = append(got, f)
+		return nil
+	})
+	assert.Nil(t, err)
+	expected := Filters{
+		{
+			Criteria: Criteria{From: "b@x.com"},
+			Action:   Actions{Star: true},
+		},
+	}
+	assert.Equal(t, expected, got)
+}
+
+func TestFromRulesStreamAbort(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"a@x.com"},
+			},
+			Actions: parser.Actions{Labels: []string{"l1", "l2", "l3"}},
+		},
+	}
+
+	emitErr := errors.New("disk full")
+	count := 0
+	err := FromRulesStream(rules, GenerateOptions{}, func(f Filter) error {
+		count++
+		if count == 2 {
+			return emitErr
+		}
+		return nil
+	})
+	assert.Equal(t, emitErr, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestLabelPrefix(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"a"},
+			},
+			Actions: parser.Actions{
+				Labels: []string{"work", "auto/news"},
+			},
+		},
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"b"},
+			},
+			Actions: parser.Actions{
+				Archive: true,
+			},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{From: "a"},
+			Action:   Actions{AddLabel: "auto/work"},
+		},
+		{
+			Criteria: Criteria{From: "a"},
+			// Already prefixed
+			Action: Actions{AddLabel: "auto/news"},
+		},
+		{
+			Criteria: Criteria{From: "b"},
+			Action:   Actions{Archive: true},
+		},
+	}
+	got, err := FromRulesWithOptions(rules, GenerateOptions{LabelPrefix: "auto/"})
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+
+	// Prefixing again has no effect
+	assert.Equal(t, expected, PrefixLabels(got, "auto/"))
+}
+
+func TestFromConfigDefaultOptions(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/generate_default.yaml")
+	assert.Nil(t, err)
+	var config cfg.Config
+	assert.Nil(t, yaml.UnmarshalStrict(b, &config))
+
+	// The zero value of the options must keep producing the same output.
+	got, err := FromConfig(config, GenerateOptions{})
+	assert.Nil(t, err)
+
+	golden := "testdata/generate_default.golden"
+	if *update {
+		err = ioutil.WriteFile(golden, []byte(got.String()), 0644)
+		assert.Nil(t, err)
+		return
+	}
+	out, err := ioutil.ReadFile(golden)
+	assert.Nil(t, err)
+	assert.Equal(t, string(out), got.String())
+}
+
+func TestDedupeOption(t *testing.T) {
+	rule := parser.Rule{
+		Criteria: &parser.Leaf{
+			Function: parser.FunctionFrom,
+			Args:     []string{"a"},
+		},
+		Actions: parser.Actions{Archive: true},
+	}
+	rules := []parser.Rule{rule, rule}
+
+	got, err := FromRulesWithOptions(rules, GenerateOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, got, 2)
+
+	got, err = FromRulesWithOptions(rules, GenerateOptions{Dedupe: true})
+	assert.Nil(t, err)
+	assert.Len(t, got, 1)
+}
+
+func TestGenerateCriteriaWithOptions(t *testing.T) {
+	crit := &parser.Leaf{
+		Function: parser.FunctionFrom,
+		Grouping: parser.OperationOr,
+		Args:     []string{"b@x.com", "a@x.com"},
+	}
+	got, err := GenerateCriteriaWithOptions(crit, GenerateOptions{OrStyle: OrKeyword, SortOr: true})
+	assert.Nil(t, err)
+	assert.Equal(t, Criteria{From: "(a@x.com OR b@x.com)"}, got)
+}
@@ -1,6 +1,7 @@
 package filter
 
 import (
+	"crypto/md5"
 	"fmt"
 	"sort"
 	"strings"
@@ -8,6 +9,7 @@ import (
 	"github.com/cnf/structhash"
 	"github.com/pmezard/go-difflib/difflib"
 
+	"github.com/mbrt/gmailctl/pkg/gmail"
 	"github.com/mbrt/gmailctl/pkg/graph"
 )
 
@@ -61,6 +63,54 @@ func (f FiltersDiff) String() string {
 	return s
 }
 
+// Plan returns a textual description of the changes: the number of
+// filters to be created and deleted, followed by the filters themselves.
+func (f FiltersDiff) Plan() string {
+	if f.Empty() {
+		return "No changes.\n"
+	}
+	return fmt.Sprintf("Filters to create: %d, to delete: %d\n\n%s",
+		len(f.Added), len(f.Removed), FormatDiff(f.Added, f.Removed, false))
+}
+
+// ANSI escape codes used to color the diff summary.
+const (
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorReset = "\x1b[0m"
+)
+
+// FormatDiff returns a summary of the given removed and added filters,
+// where every line of a removed filter is prefixed by '-' and every line of
+// an added one by '+'.
+//
+// If color is true, removed filters are printed in red and added ones in
+// green. The output only depends on the order of the given filters.
+func FormatDiff(added, removed Filters, color bool) string {
+	w := writer{}
+	writeFilters := func(fs Filters, prefix, c string) {
+		for _, f := range fs {
+			for _, line := range strings.SplitAfter(f.String(), "\n") {
+				if line == "" {
+					continue
+				}
+				if color {
+					w.WriteString(c)
+				}
+				w.WriteString(prefix)
+				w.WriteString(strings.TrimSuffix(line, "\n"))
+				if color {
+					w.WriteString(colorReset)
+				}
+				w.WriteRune('\n')
+			}
+		}
+	}
+	writeFilters(removed, "- ", colorRed)
+	writeFilters(added, "+ ", colorGreen)
+	return w.String()
+}
+
 func changedFilters(upstream, local Filters) (added, removed Filters) {
 	hupstream := newHashedFilters(upstream)
 	hlocal := newHashedFilters(local)
@@ -139,16 +189,104 @@ func newHashedFilters(fs Filters) hashedFilters {
 	return res
 }
 
+// Dedupe returns a copy of the filters without duplicates.
+//
+// Filters are compared by contents only, ignoring IDs. The order of the first
+// occurrence of each filter is preserved.
+func Dedupe(fs Filters) Filters {
+	res := Filters{}
+	seen := map[string]struct{}{}
+	for _, f := range fs {
+		hf := hashFilter(f)
+		if _, ok := seen[hf.hash]; ok {
+			continue
+		}
+		seen[hf.hash] = struct{}{}
+		res = append(res, f)
+	}
+	return res
+}
+
+// Fingerprint returns a stable hash of the contents of the filter, useful to
+// detect changes without computing a full diff.
+//
+// IDs, names and irrelevant differences removed by Normalize are ignored, so
+// the filters considered equal by Diff and Dedupe share the same fingerprint.
+func Fingerprint(f Filter) string {
+	return hashFilter(f).hash
+}
+
+// hashVersion is the version of the hashed contents of the filters. Bump it
+// whenever hashedContents changes in an incompatible way.
+const hashVersion = 1
+
+// hashedContents is the subset of a filter that is hashed.
+//
+// The hash determines the order of the filters in the diffs, so it's kept
+// separate from Filter, and mirrors its original layout. Adding fields to
+// Filter doesn't change the hashes, nor the diffs.
+type hashedContents struct {
+	ID       string
+	Action   hashedActions
+	Criteria hashedCriteria
+}
+
+// hashedActions are the actions originally supported by Filter.
+//
+// Actions added later are hashed only when set, so that the filters not using
+// them keep their hashes.
+type hashedActions struct {
+	AddLabel         string
+	Category         gmail.Category
+	Archive          bool
+	Delete           bool
+	MarkImportant    bool
+	MarkNotImportant bool
+	MarkRead         bool
+	MarkNotSpam      bool
+	Star             bool
+}
+
+type hashedCriteria struct {
+	From    string
+	To      string
+	Subject string
+	Query   string
+}
+
 func hashFilter(f Filter) hashedFilter {
-	// We have to hash only the contents, not the ID
-	noIDFilter := Filter{
-		Action:   f.Action,
-		Criteria: f.Criteria,
+	// We have to hash only the normalized contents, not the ID
+	nf := Normalize(f)
+	a := nf.Action
+	contents := hashedContents{
+		Action: hashedActions{
+			AddLabel:         a.AddLabel,
+			Category:         a.Category,
+			Archive:          a.Archive,
+			Delete:           a.Delete,
+			MarkImportant:    a.MarkImportant,
+			MarkNotImportant: a.MarkNotImportant,
+			MarkRead:         a.MarkRead,
+			MarkNotSpam:      a.MarkNotSpam,
+			Star:             a.Star,
+		},
+		Criteria: hashedCriteria(nf.Criteria),
 	}
-	h, err := structhash.Hash(noIDFilter, 1)
-	if err != nil {
-		panic("hash cannot fail, unreachable")
+	dump := structhash.Dump(contents, hashVersion)
+
+	extra := map[string]string{}
+	if a.RemoveLabel != "" {
+		extra["RemoveLabel"] = a.RemoveLabel
+	}
+	if a.Forward != "" {
+		extra["Forward"] = a.Forward
 	}
+	if len(extra) > 0 {
+		dump = append(dump, structhash.Dump(extra, hashVersion)...)
+	}
+
+	// Same format as structhash.Hash
+	h := fmt.Sprintf("v%d_%x", hashVersion, md5.Sum(dump))
 	return hashedFilter{h, f}
 }
 
@@ -1,14 +1,21 @@
 package filter
 
 import (
+	"flag"
+	"fmt"
+	"io/ioutil"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/mbrt/gmailctl/pkg/gmail"
+	"github.com/mbrt/gmailctl/pkg/parser"
 )
 
+// update is useful to regenerate the golden files, whenever necessary.
+var update = flag.Bool("update", false, "update golden files")
+
 func TestNoDiff(t *testing.T) {
 	old := Filters{
 		{
@@ -322,3 +329,190 @@ func TestDuplicate(t *testing.T) {
 	// Only one of the two identical filters is present
 	assert.Equal(t, new[1:], fd.Added)
 }
+
+func TestDedupe(t *testing.T) {
+	fs := Filters{
+		{
+			Criteria: Criteria{From: "a"},
+			Action:   Actions{Archive: true},
+		},
+		{
+			Criteria: Criteria{From: "b"},
+			Action:   Actions{Archive: true},
+		},
+		{
+			ID:       "abcdefg",
+			Criteria: Criteria{From: "a"},
+			Action:   Actions{Archive: true},
+		},
+		{
+			Criteria: Criteria{From: "a"},
+			Action:   Actions{Archive: true, MarkRead: true},
+		},
+	}
+	expected := Filters{fs[0], fs[1], fs[3]}
+	assert.Equal(t, expected, Dedupe(fs))
+}
+
+func TestDiffMixed(t *testing.T) {
+	old := someFilters()
+	for i := range old {
+		old[i].ID = fmt.Sprintf("id%d", i)
+	}
+	// Unchanged filters in a different order and without IDs, plus a new one,
+	// while the second one is gone.
+	new := Filters{
+		{
+			Criteria: Criteria{
+				Subject: "new stuff",
+			},
+			Action: Actions{
+				Archive: true,
+			},
+		},
+		old[2],
+		old[0],
+	}
+	new[1].ID = ""
+	new[2].ID = ""
+
+	fd, err := Diff(old, new)
+	expected := FiltersDiff{
+		Added:   Filters{new[0]},
+		Removed: Filters{old[1]},
+	}
+	assert.Nil(t, err)
+	assert.Equal(t, expected, fd)
+}
+
+func TestFormatDiff(t *testing.T) {
+	removed := someFilters()[:2]
+	added := Filters{
+		{
+			Criteria: Criteria{
+				From:    "{someone@gmail.com else@gmail.com}",
+				Subject: "news",
+			},
+			Action: Actions{
+				Archive:  true,
+				AddLabel: "label1",
+			},
+		},
+		{
+			Criteria: Criteria{
+				Query: "list:mylist@gmail.com",
+			},
+			Action: Actions{
+				Category: gmail.CategoryForums,
+			},
+		},
+	}
+
+	for _, color := range []bool{false, true} {
+		got := FormatDiff(added, removed, color)
+		golden := "testdata/diff_summary.golden"
+		if color {
+			golden = "testdata/diff_summary_color.golden"
+		}
+		if *update {
+			err := ioutil.WriteFile(golden, []byte(got), 0644)
+			assert.Nil(t, err)
+			continue
+		}
+		expected, err := ioutil.ReadFile(golden)
+		assert.Nil(t, err)
+		assert.Equal(t, string(expected), got)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	fd, err := Diff(Filters{}, someFilters()[:2])
+	assert.Nil(t, err)
+	expected := `Filters to create: 2, to delete: 0
+
++ * Criteria:
++     to: me@gmail.com
++   Actions:
++     mark as read
++     apply label: label2
++ * Criteria:
++     from: someone@gmail.com
++   Actions:
++     apply label: label1
+`
+	assert.Equal(t, expected, fd.Plan())
+
+	fd, err = Diff(someFilters(), someFilters())
+	assert.Nil(t, err)
+	assert.Equal(t, "No changes.\n", fd.Plan())
+}
+
+func TestFormatDiffName(t *testing.T) {
+	rule := parser.Rule{
+		Name: "Newsletters",
+		Criteria: &parser.Leaf{
+			Function: parser.FunctionFrom,
+			Args:     []string{"news@x.com"},
+		},
+		Actions: parser.Actions{Archive: true},
+	}
+	local, err := FromRule(rule)
+	assert.Nil(t, err)
+	assert.Equal(t, "Newsletters", local[0].Name)
+
+	upstream := Filters{
+		{
+			ID:       "abcdefg",
+			Criteria: local[0].Criteria,
+			Action:   local[0].Action,
+		},
+	}
+	// The name is not stored by Gmail, so it doesn't make a difference.
+	fd, err := Diff(upstream, local)
+	assert.Nil(t, err)
+	assert.True(t, fd.Empty())
+
+	fd, err = Diff(Filters{}, local)
+	assert.Nil(t, err)
+	expected := `+ # Newsletters
++ * Criteria:
++     from: news@x.com
++   Actions:
++     archive
+`
+	assert.Equal(t, expected, FormatDiff(fd.Added, fd.Removed, false))
+}
+
+func TestFingerprint(t *testing.T) {
+	f1 := Filter{
+		ID:       "abcd",
+		Name:     "news",
+		Criteria: Criteria{From: "news@x.com"},
+		Action:   Actions{Archive: true, AddLabel: "news"},
+	}
+	f2 := Filter{
+		ID:       "efgh",
+		Criteria: Criteria{From: " news@x.com "},
+		Action:   Actions{AddLabel: "news ", Archive: true},
+	}
+	f3 := Filter{
+		Criteria: Criteria{From: "news@x.com"},
+		Action:   Actions{Archive: true, AddLabel: "other"},
+	}
+
+	assert.Equal(t, Fingerprint(f1), Fingerprint(f2))
+	assert.NotEqual(t, Fingerprint(f1), Fingerprint(f3))
+	// Consistent with Dedupe
+	assert.Len(t, Dedupe(Filters{f1, f2, f3}), 2)
+}
+
+func TestFingerprintStable(t *testing.T) {
+	f := Filter{
+		Name:     "news",
+		Criteria: Criteria{From: "news@x.com"},
+		Action:   Actions{Archive: true, AddLabel: "news"},
+	}
+	// The hash determines the order of the filters in the diffs, so it
+	// shouldn't change when unrelated fields are added to Filter.
+	assert.Equal(t, "v1_f3f5fb7ccfcbe9a7882049a0fb4d10c1", Fingerprint(f))
+}
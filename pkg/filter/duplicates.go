@@ -0,0 +1,80 @@
+package filter
+
+import (
+	"fmt"
+	"sort"
+
+	cfg "github.com/mbrt/gmailctl/pkg/config/v1alpha2"
+	"github.com/mbrt/gmailctl/pkg/parser"
+)
+
+// FindDuplicateRules returns the groups of rules generating at least one
+// identical filter, identified by their position in the config. Such rules
+// can usually be consolidated.
+//
+// Filters are compared by their fingerprint, after sorting the values in
+// OR, so rules written differently are detected as well. Invalid and
+// disabled rules are ignored.
+func FindDuplicateRules(config cfg.Config) [][]int {
+	// Invalid rules are reported elsewhere, skip them
+	rules, _ := parser.ParseRules(config)
+	return FindDuplicates(rules)
+}
+
+// FindDuplicates is like FindDuplicateRules, but works on the rules returned
+// by parser.ParseRules.
+func FindDuplicates(rules []parser.Rule) [][]int {
+	var hashes []string
+	byHash := map[string][]int{}
+	for i, rule := range rules {
+		if rule.Criteria == nil {
+			continue
+		}
+		rule.Criteria = sortOrArgs(rule.Criteria)
+		fs, err := fromRule(rule, OrBraces)
+		if err != nil {
+			continue
+		}
+		for _, f := range fs {
+			h := Fingerprint(f)
+			idxs, ok := byHash[h]
+			if !ok {
+				hashes = append(hashes, h)
+			}
+			if len(idxs) > 0 && idxs[len(idxs)-1] == i {
+				// The same rule can't duplicate itself
+				continue
+			}
+			byHash[h] = append(idxs, i)
+		}
+	}
+
+	var res [][]int
+	seen := map[string]struct{}{}
+	for _, h := range hashes {
+		group := byHash[h]
+		if len(group) < 2 {
+			continue
+		}
+		key := fmt.Sprint(group)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		res = append(res, group)
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return lessInts(res[i], res[j])
+	})
+	return res
+}
+
+// lessInts compares the two slices lexicographically.
+func lessInts(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
@@ -0,0 +1,100 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	cfg "github.com/mbrt/gmailctl/pkg/config/v1alpha2"
+)
+
+func TestFindDuplicateRules(t *testing.T) {
+	config := cfg.Config{
+		Version: cfg.Version,
+		Rules: []cfg.Rule{
+			{
+				Filter: cfg.FilterNode{
+					And: []cfg.FilterNode{
+						{From: "a@x.com"},
+						{Subject: "invoice"},
+					},
+				},
+				Actions: cfg.Actions{Labels: []string{"finance"}},
+			},
+			{
+				Filter:  cfg.FilterNode{From: "other@x.com"},
+				Actions: cfg.Actions{Archive: true},
+			},
+			{
+				// Same as the first rule, written differently
+				Filter: cfg.FilterNode{
+					And: []cfg.FilterNode{
+						{Subject: "invoice"},
+						{From: "a@x.com"},
+					},
+				},
+				Actions: cfg.Actions{Labels: []string{"finance"}},
+			},
+			{
+				// Same as the second rule, with a redundant 'or'
+				Filter: cfg.FilterNode{
+					Or: []cfg.FilterNode{
+						{From: "other@x.com"},
+					},
+				},
+				Actions: cfg.Actions{Archive: true},
+			},
+		},
+	}
+	expected := [][]int{{0, 2}, {1, 3}}
+	assert.Equal(t, expected, FindDuplicateRules(config))
+}
+
+func TestFindDuplicateRulesOrOrder(t *testing.T) {
+	config := cfg.Config{
+		Version: cfg.Version,
+		Rules: []cfg.Rule{
+			{
+				Filter: cfg.FilterNode{
+					Or: []cfg.FilterNode{
+						{From: "a@x.com"},
+						{From: "b@x.com"},
+					},
+				},
+				Actions: cfg.Actions{Labels: []string{"l1", "l2"}},
+			},
+			{
+				Filter: cfg.FilterNode{
+					Or: []cfg.FilterNode{
+						{From: "b@x.com"},
+						{From: "a@x.com"},
+					},
+				},
+				Actions: cfg.Actions{Labels: []string{"l2"}},
+			},
+			{
+				// Invalid rules are ignored
+				Filter: cfg.FilterNode{},
+			},
+		},
+	}
+	// Only the filter applying 'l2' is duplicated
+	assert.Equal(t, [][]int{{0, 1}}, FindDuplicateRules(config))
+}
+
+func TestFindDuplicateRulesNone(t *testing.T) {
+	config := cfg.Config{
+		Version: cfg.Version,
+		Rules: []cfg.Rule{
+			{
+				Filter:  cfg.FilterNode{From: "a@x.com"},
+				Actions: cfg.Actions{Archive: true},
+			},
+			{
+				Filter:  cfg.FilterNode{From: "a@x.com"},
+				Actions: cfg.Actions{Star: true},
+			},
+		},
+	}
+	assert.Empty(t, FindDuplicateRules(config))
+}
@@ -0,0 +1,96 @@
+package filter
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// EmailMeta contains the metadata of an email, used to simulate locally
+// which filters would match it.
+type EmailMeta struct {
+	From string
+	// To contains all the recipients, including cc and bcc.
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Match describes a filter matching an email.
+type Match struct {
+	// Index is the position of the filter in the list.
+	Index  int
+	Filter Filter
+	// Properties contains the names of the criteria satisfied by the email.
+	Properties []string
+}
+
+// Explain returns the filters matching the given email, together with the
+// criteria satisfied by it.
+//
+// This is a local simulation, which supports only the subset of the Gmail
+// search syntax described in MatchQuery.
+func Explain(fs Filters, email EmailMeta) ([]Match, error) {
+	var res []Match
+	for i, f := range fs {
+		props, err := matchCriteria(f.Criteria, email)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error in filter #%d", i)
+		}
+		if len(props) > 0 {
+			res = append(res, Match{Index: i, Filter: f, Properties: props})
+		}
+	}
+	return res, nil
+}
+
+// matchCriteria returns the names of the criteria satisfied by the email,
+// or nothing if one of them is not.
+func matchCriteria(c Criteria, e EmailMeta) ([]string, error) {
+	fields := []struct {
+		name  string
+		query string
+	}{
+		{"from", prefixQuery("from", c.From)},
+		{"to", prefixQuery("to", c.To)},
+		{"subject", prefixQuery("subject", c.Subject)},
+		{"query", c.Query},
+	}
+
+	var res []string
+	matched := true
+	for _, f := range fields {
+		if f.query == "" {
+			continue
+		}
+		// Evaluate all the fields, to detect unsupported values even when
+		// the email doesn't match.
+		ok, err := MatchQuery(f.query, e)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error in '%s'", f.name)
+		}
+		matched = matched && ok
+		res = append(res, f.name)
+	}
+	if !matched {
+		return nil, nil
+	}
+	return res, nil
+}
+
+func prefixQuery(op, value string) string {
+	if value == "" {
+		return ""
+	}
+	return op + ":" + value
+}
+
+func containsTerm(texts []string, term string) bool {
+	term = strings.ToLower(term)
+	for _, t := range texts {
+		if strings.Contains(strings.ToLower(t), term) {
+			return true
+		}
+	}
+	return false
+}
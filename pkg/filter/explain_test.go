@@ -0,0 +1,58 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	fs := Filters{
+		{
+			Criteria: Criteria{From: "{news@x.com promo@x.com}", Subject: `"weekly digest"`},
+			Action:   Actions{Archive: true},
+		},
+		{
+			Criteria: Criteria{To: "me@gmail.com", Query: "(unsubscribe offer)"},
+			Action:   Actions{AddLabel: "promo"},
+		},
+		{
+			Criteria: Criteria{From: "boss@x.com"},
+			Action:   Actions{MarkImportant: true},
+		},
+	}
+	email := EmailMeta{
+		From:    "Newsletter <News@x.com>",
+		To:      []string{"me@gmail.com"},
+		Subject: "Your Weekly Digest",
+		Body:    "A special offer for you. Click here to unsubscribe.",
+	}
+	expected := []Match{
+		{Index: 0, Filter: fs[0], Properties: []string{"from", "subject"}},
+		{Index: 1, Filter: fs[1], Properties: []string{"to", "query"}},
+	}
+	got, err := Explain(fs, email)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestExplainNoMatch(t *testing.T) {
+	fs := Filters{
+		{
+			// Only part of the criteria is satisfied
+			Criteria: Criteria{From: "news@x.com", Subject: "digest"},
+			Action:   Actions{Archive: true},
+		},
+	}
+	email := EmailMeta{
+		From:    "news@x.com",
+		Subject: "Breaking news",
+	}
+	got, err := Explain(fs, email)
+	assert.Nil(t, err)
+	assert.Empty(t, got)
+
+	fs[0].Criteria.Query = "list:foo"
+	_, err = Explain(fs, email)
+	assert.EqualError(t, err, "error in filter #0: error in 'query': unsupported operator 'list:'")
+}
@@ -2,11 +2,15 @@ package filter
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/mbrt/gmailctl/pkg/gmail"
 )
 
+// labelSeparator separates the components of nested labels.
+const labelSeparator = "/"
+
 // Filters is a list of filters created in Gmail.
 type Filters []Filter
 
@@ -25,10 +29,62 @@ func (fs Filters) String() string {
 	return w.String()
 }
 
+// RequiredLabels returns the sorted list of labels that need to be present
+// in Gmail for the filters to be applied.
+//
+// Nested labels need their parents to exist as well, so they are included
+// in the result. For example 'Work/Invoices' requires both 'Work' and
+// 'Work/Invoices'.
+func (fs Filters) RequiredLabels() []string {
+	labels := map[string]struct{}{}
+	for _, f := range fs {
+		for _, l := range []string{f.Action.AddLabel, f.Action.RemoveLabel} {
+			if l == "" {
+				continue
+			}
+			parts := strings.Split(l, labelSeparator)
+			for i := range parts {
+				labels[strings.Join(parts[:i+1], labelSeparator)] = struct{}{}
+			}
+		}
+	}
+
+	res := []string{}
+	for l := range labels {
+		res = append(res, l)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// MapLabelsToFolders returns a copy of the filters, with the labels
+// renamed according to the given mapping. This is useful for clients
+// using a different naming scheme, such as IMAP folders (e.g. 'Work/Invoices'
+// becoming 'INBOX.Work.Invoices').
+//
+// Labels not present in the mapping are left unchanged.
+func MapLabelsToFolders(fs Filters, mapping map[string]string) Filters {
+	var res Filters
+	for _, f := range fs {
+		if folder, ok := mapping[f.Action.AddLabel]; ok && f.Action.AddLabel != "" {
+			f.Action.AddLabel = folder
+		}
+		if folder, ok := mapping[f.Action.RemoveLabel]; ok && f.Action.RemoveLabel != "" {
+			f.Action.RemoveLabel = folder
+		}
+		res = append(res, f)
+	}
+	return res
+}
+
 // Filter matches 1:1 a filter created on Gmail.
 type Filter struct {
 	// ID is an optional identifier associated with a filter.
-	ID       string
+	ID string
+	// Name is the optional name of the rule that generated the filter.
+	//
+	// Gmail doesn't store it, so it's ignored when comparing filters.
+	Name     string
 	Action   Actions
 	Criteria Criteria
 }
@@ -36,6 +92,11 @@ type Filter struct {
 func (f Filter) String() string {
 	w := writer{}
 
+	if f.Name != "" {
+		w.WriteString("# ")
+		w.WriteString(f.Name)
+		w.WriteRune('\n')
+	}
 	w.WriteString("* Criteria:\n")
 	w.WriteParam("from", f.Criteria.From)
 	w.WriteParam("to", f.Criteria.To)
@@ -52,14 +113,35 @@ func (f Filter) String() string {
 	w.WriteBool("star", f.Action.Star)
 	w.WriteParam("categorize as", string(f.Action.Category))
 	w.WriteParam("apply label", f.Action.AddLabel)
+	w.WriteParam("remove label", f.Action.RemoveLabel)
+	w.WriteParam("forward to", f.Action.Forward)
 
 	return w.String()
 }
 
+// Normalize returns the canonical form of the filter, where irrelevant
+// differences (e.g. leading and trailing whitespace) are removed.
+//
+// Two filters with the same normalized contents are equivalent for Gmail.
+func Normalize(f Filter) Filter {
+	f.Criteria = Criteria{
+		From:    strings.TrimSpace(f.Criteria.From),
+		To:      strings.TrimSpace(f.Criteria.To),
+		Subject: strings.TrimSpace(f.Criteria.Subject),
+		Query:   strings.TrimSpace(f.Criteria.Query),
+	}
+	f.Action.AddLabel = strings.TrimSpace(f.Action.AddLabel)
+	f.Action.RemoveLabel = strings.TrimSpace(f.Action.RemoveLabel)
+	f.Action.Forward = strings.TrimSpace(f.Action.Forward)
+	return f
+}
+
 // Actions represents an action associated with a Gmail filter.
 type Actions struct {
 	AddLabel         string
+	RemoveLabel      string
 	Category         gmail.Category
+	Forward          string
 	Archive          bool
 	Delete           bool
 	MarkImportant    bool
@@ -98,7 +180,7 @@ func (c Criteria) ToGmailSearch() string {
 		res = append(res, fmt.Sprintf("to:%s", c.To))
 	}
 	if c.Subject != "" {
-		res = append(res, fmt.Sprintf("subject:%s", c.To))
+		res = append(res, fmt.Sprintf("subject:%s", c.Subject))
 	}
 	if c.Query != "" {
 		res = append(res, c.Query)
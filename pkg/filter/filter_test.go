@@ -0,0 +1,183 @@
+package filter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mbrt/gmailctl/pkg/gmail"
+	"github.com/mbrt/gmailctl/pkg/parser"
+)
+
+func TestRequiredLabels(t *testing.T) {
+	fs := Filters{
+		{
+			Criteria: Criteria{From: "a"},
+			Action:   Actions{AddLabel: "Work/Invoices/2024"},
+		},
+		{
+			Criteria: Criteria{From: "b"},
+			Action:   Actions{AddLabel: "Work/Invoices"},
+		},
+		{
+			Criteria: Criteria{From: "c"},
+			Action:   Actions{AddLabel: "Personal"},
+		},
+		{
+			Criteria: Criteria{From: "d"},
+			Action:   Actions{Archive: true},
+		},
+	}
+	expected := []string{
+		"Personal",
+		"Work",
+		"Work/Invoices",
+		"Work/Invoices/2024",
+	}
+	assert.Equal(t, expected, fs.RequiredLabels())
+}
+
+func TestMapLabelsToFolders(t *testing.T) {
+	fs := Filters{
+		{
+			Criteria: Criteria{From: "a"},
+			Action:   Actions{AddLabel: "Work/Invoices"},
+		},
+		{
+			Criteria: Criteria{From: "b"},
+			Action:   Actions{AddLabel: "Personal"},
+		},
+		{
+			Criteria: Criteria{From: "c"},
+			Action:   Actions{Archive: true},
+		},
+	}
+	mapping := map[string]string{
+		"Work/Invoices": "INBOX.Work.Invoices",
+		"":              "INBOX.Unused",
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{From: "a"},
+			Action:   Actions{AddLabel: "INBOX.Work.Invoices"},
+		},
+		{
+			Criteria: Criteria{From: "b"},
+			Action:   Actions{AddLabel: "Personal"},
+		},
+		{
+			Criteria: Criteria{From: "c"},
+			Action:   Actions{Archive: true},
+		},
+	}
+	assert.Equal(t, expected, MapLabelsToFolders(fs, mapping))
+	// The original is untouched
+	assert.Equal(t, "Work/Invoices", fs[0].Action.AddLabel)
+}
+
+func TestNormalize(t *testing.T) {
+	f := Filter{
+		ID:   "abc",
+		Name: "rule",
+		Criteria: Criteria{
+			From:  " a@x.com",
+			Query: "list:foo \t",
+		},
+		Action: Actions{
+			AddLabel: "label1 ",
+			Archive:  true,
+		},
+	}
+	expected := Filter{
+		ID:   "abc",
+		Name: "rule",
+		Criteria: Criteria{
+			From:  "a@x.com",
+			Query: "list:foo",
+		},
+		Action: Actions{
+			AddLabel: "label1",
+			Archive:  true,
+		},
+	}
+	n := Normalize(f)
+	assert.Equal(t, expected, n)
+	assert.Equal(t, n, Normalize(n))
+
+	// Equivalent filters are considered equal by Diff and Dedupe.
+	fd, err := Diff(Filters{f}, Filters{expected})
+	assert.Nil(t, err)
+	assert.True(t, fd.Empty())
+	assert.Len(t, Dedupe(Filters{f, expected}), 1)
+}
+
+func TestFilterString(t *testing.T) {
+	f := Filter{
+		ID:   "abcdefg",
+		Name: "Work",
+		Criteria: Criteria{
+			From:    "{a@x.com b@x.com}",
+			Subject: "hi",
+			Query:   "-list:foo",
+		},
+		Action: Actions{
+			Archive:     true,
+			MarkNotSpam: true,
+			Category:    gmail.CategoryUpdates,
+			AddLabel:    "Work",
+			Forward:     "me@x.com",
+		},
+	}
+	expected := `# Work
+* Criteria:
+    from: {a@x.com b@x.com}
+    subject: hi
+    query: -list:foo
+  Actions:
+    archive
+    never mark as spam
+    categorize as: updates
+    apply label: Work
+    forward to: me@x.com
+`
+	assert.Equal(t, expected, f.String())
+	assert.Equal(t, expected, fmt.Sprintf("%v", f))
+}
+
+func TestToGmailSearch(t *testing.T) {
+	c := Criteria{
+		From:    "{a@x.com b@x.com}",
+		To:      "me@x.com",
+		Subject: `"hello world"`,
+		Query:   "list:foo -{bar baz}",
+	}
+	expected := `from:{a@x.com b@x.com} to:me@x.com subject:"hello world" list:foo -{bar baz}`
+	assert.Equal(t, expected, c.ToGmailSearch())
+
+	assert.Equal(t, "subject:hi", Criteria{Subject: "hi"}.ToGmailSearch())
+	assert.Equal(t, "has:attachment", Criteria{Query: "has:attachment"}.ToGmailSearch())
+}
+
+func TestToGmailSearchGenerated(t *testing.T) {
+	rule := parser.Rule{
+		Criteria: &parser.Node{
+			Operation: parser.OperationAnd,
+			Children: []parser.CriteriaAST{
+				&parser.Leaf{
+					Function: parser.FunctionSubject,
+					Grouping: parser.OperationOr,
+					Args:     []string{"status {ok}", "done"},
+				},
+				&parser.Leaf{
+					Function: parser.FunctionList,
+					Args:     []string{"team@x.com"},
+				},
+			},
+		},
+		Actions: parser.Actions{Archive: true},
+	}
+	fs, err := FromRule(rule)
+	assert.Nil(t, err)
+	assert.Equal(t, `subject:{"status {ok}" done} list:team@x.com`, fs[0].Criteria.ToGmailSearch())
+}
@@ -0,0 +1,128 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	cfg "github.com/mbrt/gmailctl/pkg/config/v1alpha2"
+)
+
+// ToConfigRules converts filters back into config rules.
+//
+// The conversion is best-effort: Gmail filters are flat, so the result is
+// not going to be as compact as a hand written config. Filters with the same
+// criteria are merged back in a single rule, reverting the split done to
+// apply multiple labels.
+func ToConfigRules(fs Filters) []cfg.Rule {
+	var res []cfg.Rule
+	ruleIdx := map[Criteria]int{}
+
+	for _, f := range fs {
+		i, ok := ruleIdx[f.Criteria]
+		if !ok {
+			ruleIdx[f.Criteria] = len(res)
+			res = append(res, cfg.Rule{
+				Name:    f.Name,
+				Filter:  criteriaToNode(f.Criteria),
+				Actions: importActions(cfg.Actions{}, f.Action),
+			})
+			continue
+		}
+		res[i].Actions = importActions(res[i].Actions, f.Action)
+	}
+
+	return res
+}
+
+func criteriaToNode(c Criteria) cfg.FilterNode {
+	var nodes []cfg.FilterNode
+
+	if c.From != "" {
+		nodes = append(nodes, fieldToNode("from", c.From, func(s string) cfg.FilterNode {
+			return cfg.FilterNode{From: s}
+		}))
+	}
+	if c.To != "" {
+		nodes = append(nodes, fieldToNode("to", c.To, func(s string) cfg.FilterNode {
+			return cfg.FilterNode{To: s}
+		}))
+	}
+	if c.Subject != "" {
+		nodes = append(nodes, fieldToNode("subject", c.Subject, func(s string) cfg.FilterNode {
+			return cfg.FilterNode{Subject: s}
+		}))
+	}
+	if c.Query != "" {
+		nodes = append(nodes, cfg.FilterNode{Query: c.Query})
+	}
+
+	if len(nodes) == 1 {
+		return nodes[0]
+	}
+	return cfg.FilterNode{And: nodes}
+}
+
+// fieldToNode reverts the grouping of multiple arguments of the same
+// function, e.g. from:{a b} becomes or(from:a, from:b).
+//
+// If the value is too complex to be interpreted, it's passed verbatim
+// as a query.
+func fieldToNode(name, value string, mk func(string) cfg.FilterNode) cfg.FilterNode {
+	var grouped []cfg.FilterNode
+	isOr := strings.HasPrefix(value, "{") && strings.HasSuffix(value, "}")
+	isAnd := strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")")
+
+	if !isOr && !isAnd {
+		if terms, err := SplitTerms(value); err == nil && len(terms) == 1 {
+			return mk(terms[0].Value)
+		}
+		return cfg.FilterNode{Query: fmt.Sprintf("%s:%s", name, value)}
+	}
+
+	// Groups in parentheses can be ORs as well, e.g. '(a OR b)'.
+	terms, isOr, err := SplitGroup(value)
+	if err != nil {
+		return cfg.FilterNode{Query: fmt.Sprintf("%s:%s", name, value)}
+	}
+	for _, t := range terms {
+		grouped = append(grouped, mk(t.Value))
+	}
+	if len(grouped) == 1 {
+		return grouped[0]
+	}
+	if isOr {
+		return cfg.FilterNode{Or: grouped}
+	}
+	return cfg.FilterNode{And: grouped}
+}
+
+func importActions(res cfg.Actions, a Actions) cfg.Actions {
+	positive, negative := true, false
+
+	res.Archive = res.Archive || a.Archive
+	res.Delete = res.Delete || a.Delete
+	res.MarkRead = res.MarkRead || a.MarkRead
+	res.Star = res.Star || a.Star
+	if a.MarkImportant {
+		res.MarkImportant = &positive
+	}
+	if a.MarkNotImportant {
+		res.MarkImportant = &negative
+	}
+	if a.MarkNotSpam {
+		res.MarkSpam = &negative
+	}
+	if a.Category != "" {
+		res.Category = a.Category
+	}
+	if a.AddLabel != "" {
+		res.Labels = append(res.Labels, a.AddLabel)
+	}
+	if a.RemoveLabel != "" {
+		res.RemoveLabels = append(res.RemoveLabels, a.RemoveLabel)
+	}
+	if a.Forward != "" {
+		res.Forward = a.Forward
+	}
+	return res
+}
@@ -0,0 +1,132 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	cfg "github.com/mbrt/gmailctl/pkg/config/v1alpha2"
+	"github.com/mbrt/gmailctl/pkg/gmail"
+)
+
+func TestToConfigRulesMultiLabel(t *testing.T) {
+	fs := Filters{
+		{
+			Criteria: Criteria{From: `{a@x.com "b c"}`},
+			Action:   Actions{Archive: true, AddLabel: "l1"},
+		},
+		{
+			Criteria: Criteria{From: `{a@x.com "b c"}`},
+			Action:   Actions{AddLabel: "l2"},
+		},
+	}
+	expected := []cfg.Rule{
+		{
+			Filter: cfg.FilterNode{
+				Or: []cfg.FilterNode{
+					{From: "a@x.com"},
+					{From: "b c"},
+				},
+			},
+			Actions: cfg.Actions{
+				Archive: true,
+				Labels:  []string{"l1", "l2"},
+			},
+		},
+	}
+	assert.Equal(t, expected, ToConfigRules(fs))
+}
+
+func TestToConfigRulesName(t *testing.T) {
+	fs := Filters{
+		{
+			Name:     "newsletters",
+			Criteria: Criteria{From: "news@x.com"},
+			Action:   Actions{Archive: true},
+		},
+	}
+	expected := []cfg.Rule{
+		{
+			Name:    "newsletters",
+			Filter:  cfg.FilterNode{From: "news@x.com"},
+			Actions: cfg.Actions{Archive: true},
+		},
+	}
+	assert.Equal(t, expected, ToConfigRules(fs))
+}
+
+func TestToConfigRulesCategory(t *testing.T) {
+	fs := Filters{
+		{
+			Criteria: Criteria{
+				To:      "(me@x.com you@x.com)",
+				Subject: "hello",
+				Query:   "list:foo",
+			},
+			Action: Actions{
+				MarkNotImportant: true,
+				MarkNotSpam:      true,
+				Category:         gmail.CategoryForums,
+			},
+		},
+	}
+	expected := []cfg.Rule{
+		{
+			Filter: cfg.FilterNode{
+				And: []cfg.FilterNode{
+					{
+						And: []cfg.FilterNode{
+							{To: "me@x.com"},
+							{To: "you@x.com"},
+						},
+					},
+					{Subject: "hello"},
+					{Query: "list:foo"},
+				},
+			},
+			Actions: cfg.Actions{
+				MarkImportant: boolptr(false),
+				MarkSpam:      boolptr(false),
+				Category:      gmail.CategoryForums,
+			},
+		},
+	}
+	assert.Equal(t, expected, ToConfigRules(fs))
+}
+
+func TestToConfigRulesComplex(t *testing.T) {
+	fs := Filters{
+		{
+			Criteria: Criteria{From: "{a (b c)}"},
+			Action:   Actions{Delete: true},
+		},
+	}
+	expected := []cfg.Rule{
+		{
+			Filter:  cfg.FilterNode{Query: "from:{a (b c)}"},
+			Actions: cfg.Actions{Delete: true},
+		},
+	}
+	assert.Equal(t, expected, ToConfigRules(fs))
+}
+
+func TestToConfigRulesOrKeyword(t *testing.T) {
+	fs := Filters{
+		{
+			Criteria: Criteria{From: "(a@x.com OR b@x.com)"},
+			Action:   Actions{Archive: true},
+		},
+	}
+	expected := []cfg.Rule{
+		{
+			Filter: cfg.FilterNode{
+				Or: []cfg.FilterNode{
+					{From: "a@x.com"},
+					{From: "b@x.com"},
+				},
+			},
+			Actions: cfg.Actions{Archive: true},
+		},
+	}
+	assert.Equal(t, expected, ToConfigRules(fs))
+}
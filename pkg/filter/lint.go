@@ -0,0 +1,126 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// actionConflict is a pair of actions that have opposite effects when
+// applied to the same message.
+type actionConflict struct {
+	desc  string
+	check func(a1, a2 Actions) bool
+}
+
+var actionConflicts = []actionConflict{
+	{
+		desc:  "'archive' vs 'mark as important'",
+		check: func(a1, a2 Actions) bool { return a1.Archive && a2.MarkImportant },
+	},
+	{
+		desc:  "'mark as important' vs 'never mark as important'",
+		check: func(a1, a2 Actions) bool { return a1.MarkImportant && a2.MarkNotImportant },
+	},
+	{
+		desc:  "'delete' vs 'star'",
+		check: func(a1, a2 Actions) bool { return a1.Delete && a2.Star },
+	},
+	{
+		desc:  "'delete' vs 'apply label'",
+		check: func(a1, a2 Actions) bool { return a1.Delete && a2.AddLabel != "" },
+	},
+	{
+		desc: "different categories",
+		check: func(a1, a2 Actions) bool {
+			return a1.Category != "" && a2.Category != "" && a1.Category != a2.Category
+		},
+	},
+}
+
+// LintOverlaps returns warnings for pairs of filters matching the same
+// sender, recipient or subject, but applying conflicting actions (e.g. one
+// archives and the other marks as important).
+//
+// The rules slice contains the index of the rule generating each filter.
+// Filters generated by the same rule are not compared, as their actions are
+// meant to be applied together, and every pair of rules is reported once.
+//
+// Gmail applies all the matching filters, so the result in these cases is
+// usually confusing.
+func LintOverlaps(fs Filters, rules []int) []Warning {
+	var res []Warning
+	reported := map[[2]int]bool{}
+
+	for i := range fs {
+		for j := i + 1; j < len(fs); j++ {
+			pair := [2]int{rules[i], rules[j]}
+			if rules[i] == rules[j] || reported[pair] {
+				continue
+			}
+			field, value, ok := overlap(fs[i].Criteria, fs[j].Criteria)
+			if !ok {
+				continue
+			}
+			conflicts := conflictingActions(fs[i].Action, fs[j].Action)
+			if len(conflicts) == 0 {
+				continue
+			}
+			reported[pair] = true
+			res = append(res, Warning{
+				Message: fmt.Sprintf("rules #%d and #%d both match %s '%s' but have conflicting actions: %s",
+					rules[i], rules[j], field, value, strings.Join(conflicts, ", ")),
+			})
+		}
+	}
+
+	return res
+}
+
+// overlap returns the first field and value shared by the two criteria.
+func overlap(c1, c2 Criteria) (string, string, bool) {
+	fields := []struct {
+		name   string
+		v1, v2 string
+	}{
+		{"from", c1.From, c2.From},
+		{"to", c1.To, c2.To},
+		{"subject", c1.Subject, c2.Subject},
+	}
+	for _, f := range fields {
+		terms := fieldTerms(f.v2)
+		for _, t := range fieldTerms(f.v1) {
+			for _, t2 := range terms {
+				if strings.EqualFold(t, t2) {
+					return f.name, t, true
+				}
+			}
+		}
+	}
+	return "", "", false
+}
+
+// fieldTerms returns the values contained in a criteria field, e.g.
+// '{a b}' and '(a OR b)' contain both 'a' and 'b'.
+func fieldTerms(value string) []string {
+	if value == "" {
+		return nil
+	}
+	if terms, _, err := SplitGroup(value); err == nil {
+		var res []string
+		for _, t := range terms {
+			res = append(res, t.Value)
+		}
+		return res
+	}
+	return []string{value}
+}
+
+func conflictingActions(a1, a2 Actions) []string {
+	var res []string
+	for _, c := range actionConflicts {
+		if c.check(a1, a2) || c.check(a2, a1) {
+			res = append(res, c.desc)
+		}
+	}
+	return res
+}
@@ -0,0 +1,127 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mbrt/gmailctl/pkg/parser"
+)
+
+func TestLintOverlaps(t *testing.T) {
+	fs := Filters{
+		{
+			Criteria: Criteria{From: "{news@x.com promo@y.com}"},
+			Action:   Actions{Archive: true},
+		},
+		{
+			Criteria: Criteria{From: "News@x.com", Subject: "urgent"},
+			Action:   Actions{MarkImportant: true},
+		},
+		{
+			// Overlapping, but compatible actions
+			Criteria: Criteria{From: "promo@y.com"},
+			Action:   Actions{MarkRead: true},
+		},
+		{
+			// Conflicting actions, but no overlap
+			Criteria: Criteria{To: "me@x.com"},
+			Action:   Actions{Archive: true, Category: "updates"},
+		},
+	}
+	expected := []Warning{
+		{
+			Message: "rules #0 and #1 both match from 'news@x.com' but have conflicting actions: " +
+				"'archive' vs 'mark as important'",
+		},
+	}
+	assert.Equal(t, expected, LintOverlaps(fs, []int{0, 1, 2, 3}))
+}
+
+func TestLintOverlapsMultipleConflicts(t *testing.T) {
+	fs := Filters{
+		{
+			Criteria: Criteria{Subject: `"weekly report"`},
+			Action:   Actions{Delete: true, Category: "updates"},
+		},
+		{
+			Criteria: Criteria{Subject: `{"weekly report" digest}`},
+			Action:   Actions{AddLabel: "reports", Category: "forums"},
+		},
+	}
+	expected := []Warning{
+		{
+			Message: "rules #0 and #1 both match subject 'weekly report' but have conflicting actions: " +
+				"'delete' vs 'apply label', different categories",
+		},
+	}
+	assert.Equal(t, expected, LintOverlaps(fs, []int{0, 1}))
+}
+
+func TestLintOverlapsSameRule(t *testing.T) {
+	important := true
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"a@x.com"},
+			},
+			Actions: parser.Actions{
+				Archive:       true,
+				MarkImportant: &important,
+				Labels:        []string{"l1", "l2"},
+			},
+		},
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"b@x.com"},
+			},
+			Actions: parser.Actions{Archive: true},
+		},
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"a@x.com"},
+			},
+			Actions: parser.Actions{Delete: true},
+		},
+	}
+	// The filters split from the first rule don't conflict with each other,
+	// and its conflicts with the last rule are reported once.
+	_, warnings, err := FromRulesWithWarnings(rules, GenerateOptions{})
+	assert.Nil(t, err)
+	expected := []Warning{
+		{
+			Message: "rules #0 and #2 both match from 'a@x.com' but have conflicting actions: " +
+				"'delete' vs 'apply label'",
+		},
+	}
+	assert.Equal(t, expected, warnings)
+}
+
+func TestLintOverlapsOrKeyword(t *testing.T) {
+	important := true
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Grouping: parser.OperationOr,
+				Args:     []string{"a@x.com", "b@x.com"},
+			},
+			Actions: parser.Actions{Archive: true},
+		},
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Grouping: parser.OperationOr,
+				Args:     []string{"c@x.com", "d@x.com"},
+			},
+			Actions: parser.Actions{MarkImportant: &important},
+		},
+	}
+	// The OR keywords shared by the filters are not an overlap.
+	_, warnings, err := FromRulesWithWarnings(rules, GenerateOptions{OrStyle: OrKeyword})
+	assert.Nil(t, err)
+	assert.Empty(t, warnings)
+}
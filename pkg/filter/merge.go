@@ -0,0 +1,109 @@
+package filter
+
+import (
+	"github.com/mbrt/gmailctl/pkg/parser"
+)
+
+// MergeByCriteria merges together filters sharing the same criteria.
+//
+// This is useful when multiple rules match the same emails, but apply
+// different actions. The actions of the filters in the same group are merged
+// together and split again, so that every resulting filter still applies at
+// most one label, as Gmail requires. The label split follows the same logic
+// used when translating rules.
+//
+// Groups with conflicting actions (e.g. different categories) are left
+// untouched. The order of the first occurrence of each criteria is preserved.
+func MergeByCriteria(fs Filters) Filters {
+	var order []Criteria
+	groups := map[Criteria]Filters{}
+	for _, f := range fs {
+		if _, ok := groups[f.Criteria]; !ok {
+			order = append(order, f.Criteria)
+		}
+		groups[f.Criteria] = append(groups[f.Criteria], f)
+	}
+
+	res := Filters{}
+	for _, c := range order {
+		res = append(res, mergeGroup(c, groups[c])...)
+	}
+	return res
+}
+
+func mergeGroup(c Criteria, fs Filters) Filters {
+	if len(fs) == 1 {
+		return fs
+	}
+
+	merged, ok := mergeActions(fs)
+	if !ok {
+		return fs
+	}
+	actions, err := generateActions(merged)
+	if err != nil {
+		return fs
+	}
+	res := combineCriteriaWithActions([]Criteria{c}, actions)
+	for i := range res {
+		res[i].Name = fs[0].Name
+	}
+	return res
+}
+
+func mergeActions(fs Filters) (parser.Actions, bool) {
+	var res parser.Actions
+	var important, notImportant bool
+	seenLabels := map[string]struct{}{}
+	seenRemoved := map[string]struct{}{}
+
+	for _, f := range fs {
+		a := f.Action
+		res.Archive = res.Archive || a.Archive
+		res.Delete = res.Delete || a.Delete
+		res.MarkRead = res.MarkRead || a.MarkRead
+		res.Star = res.Star || a.Star
+		important = important || a.MarkImportant
+		notImportant = notImportant || a.MarkNotImportant
+		if a.MarkNotSpam {
+			res.MarkSpam = boolPtr(false)
+		}
+
+		if a.Category != "" {
+			if res.Category != "" && res.Category != a.Category {
+				return res, false
+			}
+			res.Category = a.Category
+		}
+		if a.Forward != "" {
+			if res.Forward != "" && res.Forward != a.Forward {
+				return res, false
+			}
+			res.Forward = a.Forward
+		}
+		if a.AddLabel != "" {
+			if _, ok := seenLabels[a.AddLabel]; !ok {
+				seenLabels[a.AddLabel] = struct{}{}
+				res.Labels = append(res.Labels, a.AddLabel)
+			}
+		}
+		if a.RemoveLabel != "" {
+			if _, ok := seenRemoved[a.RemoveLabel]; !ok {
+				seenRemoved[a.RemoveLabel] = struct{}{}
+				res.RemoveLabels = append(res.RemoveLabels, a.RemoveLabel)
+			}
+		}
+	}
+
+	if important && notImportant {
+		return res, false
+	}
+	if important || notImportant {
+		res.MarkImportant = boolPtr(important)
+	}
+	return res, true
+}
+
+func boolPtr(a bool) *bool {
+	return &a
+}
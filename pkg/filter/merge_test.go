@@ -0,0 +1,67 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mbrt/gmailctl/pkg/gmail"
+)
+
+func TestMergeByCriteria(t *testing.T) {
+	fs := Filters{
+		{
+			Criteria: Criteria{From: "a@x.com"},
+			Action:   Actions{Archive: true},
+		},
+		{
+			Criteria: Criteria{From: "b@x.com"},
+			Action:   Actions{Delete: true},
+		},
+		{
+			Criteria: Criteria{From: "a@x.com"},
+			Action:   Actions{AddLabel: "l1"},
+		},
+		{
+			Criteria: Criteria{From: "a@x.com"},
+			Action:   Actions{MarkRead: true, AddLabel: "l2"},
+		},
+	}
+	expected := Filters{
+		{
+			Criteria: Criteria{From: "a@x.com"},
+			Action:   Actions{Archive: true, MarkRead: true, AddLabel: "l1"},
+		},
+		{
+			Criteria: Criteria{From: "a@x.com"},
+			Action:   Actions{Archive: true, MarkRead: true, AddLabel: "l2"},
+		},
+		{
+			Criteria: Criteria{From: "b@x.com"},
+			Action:   Actions{Delete: true},
+		},
+	}
+	assert.Equal(t, expected, MergeByCriteria(fs))
+}
+
+func TestMergeByCriteriaConflict(t *testing.T) {
+	fs := Filters{
+		{
+			Criteria: Criteria{From: "a@x.com"},
+			Action:   Actions{Category: gmail.CategoryForums},
+		},
+		{
+			Criteria: Criteria{From: "a@x.com"},
+			Action:   Actions{Category: gmail.CategoryUpdates},
+		},
+		{
+			Criteria: Criteria{From: "b@x.com"},
+			Action:   Actions{MarkImportant: true},
+		},
+		{
+			Criteria: Criteria{From: "b@x.com"},
+			Action:   Actions{MarkNotImportant: true},
+		},
+	}
+	assert.Equal(t, fs, MergeByCriteria(fs))
+}
@@ -0,0 +1,83 @@
+package filter
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	cfg "github.com/mbrt/gmailctl/pkg/config/v1alpha2"
+)
+
+// Policy restricts the actions the rules are allowed to use, e.g. to forbid
+// destructive actions in CI.
+type Policy struct {
+	// Disallow contains the names of the forbidden actions, as they appear
+	// in the config (e.g. 'delete').
+	Disallow []string
+}
+
+// policyActions are the names of the actions a policy can refer to.
+var policyActions = []string{
+	"archive",
+	"delete",
+	"markRead",
+	"star",
+	"markSpam",
+	"markImportant",
+	"category",
+	"labels",
+	"removeLabels",
+	"forward",
+}
+
+// EnforcePolicy checks the rules of the config against the policy and
+// returns an error for every rule using disallowed actions.
+//
+// Disabled rules are checked as well, since they can be enabled at any time.
+func EnforcePolicy(config cfg.Config, policy Policy) []error {
+	for _, a := range policy.Disallow {
+		if !containsString(policyActions, a) {
+			return []error{errors.Errorf("unknown action '%s' in policy (possible values: %s)",
+				a, strings.Join(policyActions, ", "))}
+		}
+	}
+
+	var res []error
+	for i, r := range config.Rules {
+		var violations []string
+		for _, a := range usedActions(r.Actions) {
+			if containsString(policy.Disallow, a) {
+				violations = append(violations, a)
+			}
+		}
+		if len(violations) > 0 {
+			res = append(res, errors.Errorf("rule #%d: actions not allowed by the policy: %s",
+				i, strings.Join(violations, ", ")))
+		}
+	}
+	return res
+}
+
+// usedActions returns the names of the actions specified, in the same
+// order of policyActions.
+func usedActions(a cfg.Actions) []string {
+	used := map[string]bool{
+		"archive":       a.Archive,
+		"delete":        a.Delete,
+		"markRead":      a.MarkRead,
+		"star":          a.Star,
+		"markSpam":      a.MarkSpam != nil,
+		"markImportant": a.MarkImportant != nil,
+		"category":      a.Category != "",
+		"labels":        len(a.Labels) > 0,
+		"removeLabels":  len(a.RemoveLabels) > 0,
+		"forward":       a.Forward != "",
+	}
+	var res []string
+	for _, name := range policyActions {
+		if used[name] {
+			res = append(res, name)
+		}
+	}
+	return res
+}
@@ -0,0 +1,56 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	cfg "github.com/mbrt/gmailctl/pkg/config/v1alpha2"
+)
+
+func policyConfig() cfg.Config {
+	return cfg.Config{
+		Version: cfg.Version,
+		Rules: []cfg.Rule{
+			{
+				Filter:  cfg.FilterNode{From: "spammer@x.com"},
+				Actions: cfg.Actions{Delete: true},
+			},
+			{
+				Filter:  cfg.FilterNode{From: "news@x.com"},
+				Actions: cfg.Actions{Archive: true, Labels: []string{"news"}},
+			},
+			{
+				Filter: cfg.FilterNode{From: "invoices@x.com"},
+				Actions: cfg.Actions{
+					Delete:  true,
+					Forward: "accountant@x.com",
+				},
+			},
+		},
+	}
+}
+
+func TestEnforcePolicyDelete(t *testing.T) {
+	errs := EnforcePolicy(policyConfig(), Policy{Disallow: []string{"delete"}})
+	assert.Len(t, errs, 2)
+	assert.EqualError(t, errs[0], "rule #0: actions not allowed by the policy: delete")
+	assert.EqualError(t, errs[1], "rule #2: actions not allowed by the policy: delete")
+}
+
+func TestEnforcePolicyDeleteForward(t *testing.T) {
+	errs := EnforcePolicy(policyConfig(), Policy{Disallow: []string{"forward", "delete"}})
+	assert.Len(t, errs, 2)
+	assert.EqualError(t, errs[1], "rule #2: actions not allowed by the policy: delete, forward")
+}
+
+func TestEnforcePolicyAllowed(t *testing.T) {
+	assert.Empty(t, EnforcePolicy(policyConfig(), Policy{}))
+	assert.Empty(t, EnforcePolicy(policyConfig(), Policy{Disallow: []string{"star"}}))
+}
+
+func TestEnforcePolicyUnknownAction(t *testing.T) {
+	errs := EnforcePolicy(policyConfig(), Policy{Disallow: []string{"trash"}})
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "unknown action 'trash'")
+}
@@ -0,0 +1,219 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MatchQuery evaluates a Gmail search query against the given email.
+//
+// Only a subset of the Gmail search syntax is supported:
+//   - the 'from:', 'to:', 'cc:', 'bcc:' and 'subject:' operators ('cc:'
+//     and 'bcc:' look into all the recipients, like 'to:')
+//   - bare words, searched in the subject and the body
+//   - quoted phrases (e.g. "weekly digest")
+//   - OR groups in braces (e.g. {a b}) and AND groups in parentheses
+//     (e.g. (a b)), also as operator values (e.g. from:{a b})
+//   - negation with a leading '-' (e.g. -from:a)
+//
+// All values are matched as case insensitive substrings. Any other
+// operator (e.g. 'list:' or 'OR') results in an error.
+func MatchQuery(query string, email EmailMeta) (bool, error) {
+	toks, err := lexQuery(query)
+	if err != nil {
+		return false, err
+	}
+	p := queryParser{toks: toks, email: email}
+	return p.parseSeq("", tokEOF, false)
+}
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokWord
+	tokPhrase
+	tokMinus
+	tokLBrace
+	tokRBrace
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokKind
+	text string
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokPhrase:
+		return fmt.Sprintf(`"%s"`, t.text)
+	default:
+		return fmt.Sprintf("'%s'", t.text)
+	}
+}
+
+var specialTokens = map[rune]tokKind{
+	'{': tokLBrace,
+	'}': tokRBrace,
+	'(': tokLParen,
+	')': tokRParen,
+}
+
+func lexQuery(query string) ([]token, error) {
+	var res []token
+	rs := []rune(query)
+
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n':
+			i++
+		case specialTokens[r] != tokEOF:
+			res = append(res, token{specialTokens[r], string(r)})
+			i++
+		case r == '-':
+			res = append(res, token{tokMinus, "-"})
+			i++
+		case r == '"':
+			var phrase strings.Builder
+			i++
+			for ; i < len(rs) && rs[i] != '"'; i++ {
+				if rs[i] == '\\' && i+1 < len(rs) {
+					i++
+				}
+				phrase.WriteRune(rs[i])
+			}
+			if i >= len(rs) {
+				return nil, errors.New("unterminated quote")
+			}
+			res = append(res, token{tokPhrase, phrase.String()})
+			i++
+		default:
+			start := i
+			for ; i < len(rs) && !strings.ContainsRune(" \t\n{}()\"", rs[i]); i++ {
+			}
+			res = append(res, token{tokWord, string(rs[start:i])})
+		}
+	}
+
+	return append(res, token{kind: tokEOF}), nil
+}
+
+// queryOperators maps the supported operators to the fields they look
+// into.
+var queryOperators = map[string]func(EmailMeta) []string{
+	"from":    func(e EmailMeta) []string { return []string{e.From} },
+	"to":      func(e EmailMeta) []string { return e.To },
+	"cc":      func(e EmailMeta) []string { return e.To },
+	"bcc":     func(e EmailMeta) []string { return e.To },
+	"subject": func(e EmailMeta) []string { return []string{e.Subject} },
+}
+
+// queryParser evaluates the query while parsing it.
+//
+// Sub-expressions are always fully evaluated, so that unsupported syntax
+// is detected regardless of the email contents.
+type queryParser struct {
+	toks  []token
+	pos   int
+	email EmailMeta
+}
+
+func (p *queryParser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *queryParser) peek() token {
+	return p.toks[p.pos]
+}
+
+// parseSeq evaluates a sequence of expressions up to the given closing
+// token, combining them in OR or AND.
+func (p *queryParser) parseSeq(field string, end tokKind, isOr bool) (bool, error) {
+	res, empty := !isOr, true
+	for p.peek().kind != end {
+		if p.peek().kind == tokEOF {
+			return false, errors.New("unterminated group")
+		}
+		v, err := p.parseExpr(field)
+		if err != nil {
+			return false, err
+		}
+		if isOr {
+			res = res || v
+		} else {
+			res = res && v
+		}
+		empty = false
+	}
+	p.next()
+	if empty {
+		return false, errors.New("empty query")
+	}
+	return res, nil
+}
+
+func (p *queryParser) parseExpr(field string) (bool, error) {
+	if p.peek().kind == tokMinus {
+		p.next()
+		v, err := p.parsePrimary(field)
+		return !v, err
+	}
+	return p.parsePrimary(field)
+}
+
+func (p *queryParser) parsePrimary(field string) (bool, error) {
+	t := p.next()
+	switch t.kind {
+	case tokLBrace:
+		return p.parseSeq(field, tokRBrace, true)
+	case tokLParen:
+		return p.parseSeq(field, tokRParen, false)
+	case tokPhrase:
+		return p.matchTerm(field, t.text), nil
+	case tokWord:
+		return p.parseWord(field, t.text)
+	default:
+		return false, errors.Errorf("unexpected %v", t)
+	}
+}
+
+func (p *queryParser) parseWord(field, word string) (bool, error) {
+	if word == "OR" || word == "AND" || word == "AROUND" {
+		return false, errors.Errorf("unsupported operator '%s'", word)
+	}
+	idx := strings.Index(word, ":")
+	if idx < 0 {
+		return p.matchTerm(field, word), nil
+	}
+
+	op, value := word[:idx], word[idx+1:]
+	if _, ok := queryOperators[op]; !ok {
+		return false, errors.Errorf("unsupported operator '%s:'", op)
+	}
+	if field != "" {
+		return false, errors.Errorf("operator '%s:' is not allowed inside '%s:'", op, field)
+	}
+	if value == "" {
+		return p.parsePrimary(op)
+	}
+	return p.matchTerm(op, value), nil
+}
+
+func (p *queryParser) matchTerm(field, term string) bool {
+	texts := []string{p.email.Subject, p.email.Body}
+	if fn, ok := queryOperators[field]; ok {
+		texts = fn(p.email)
+	}
+	return containsTerm(texts, term)
+}
@@ -0,0 +1,67 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchQuery(t *testing.T) {
+	email := EmailMeta{
+		From:    "Newsletter <news@x.com>",
+		To:      []string{"me@gmail.com", "team@x.com"},
+		Subject: "Your weekly digest",
+		Body:    "Click here to unsubscribe.",
+	}
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{"from:news@x.com", true},
+		{"from:NEWS@X.COM", true},
+		{"from:boss@x.com", false},
+		{"to:team@x.com", true},
+		{"cc:team@x.com", true},
+		{"from:{boss@x.com news@x.com}", true},
+		{"from:(boss@x.com news@x.com)", false},
+		{"{from:boss@x.com subject:digest}", true},
+		{"(from:news@x.com subject:digest)", true},
+		{`subject:"weekly digest"`, true},
+		{`subject:"digest weekly"`, false},
+		{"unsubscribe", true},
+		{"weekly unsubscribe", true},
+		{"weekly offer", false},
+		{`"click here"`, true},
+		{"-from:boss@x.com", true},
+		{"-{from:boss@x.com unsubscribe}", false},
+		{"from:x.com -from:news@x.com", false},
+		{"from:{boss@x.com -news@x.com}", false},
+		{"e-mail", false},
+	}
+	for _, tc := range tests {
+		got, err := MatchQuery(tc.query, email)
+		assert.Nil(t, err, tc.query)
+		assert.Equal(t, tc.expected, got, tc.query)
+	}
+}
+
+func TestMatchQueryErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		err   string
+	}{
+		{"list:golang", "unsupported operator 'list:'"},
+		{"a OR b", "unsupported operator 'OR'"},
+		{"from:(a subject:b)", "operator 'subject:' is not allowed inside 'from:'"},
+		{"{a b", "unterminated group"},
+		{"a}", "unexpected '}'"},
+		{`subject:"a`, "unterminated quote"},
+		{"()", "empty query"},
+		{"", "empty query"},
+		{"from:", "unexpected end of query"},
+	}
+	for _, tc := range tests {
+		_, err := MatchQuery(tc.query, EmailMeta{})
+		assert.EqualError(t, err, tc.err, tc.query)
+	}
+}
@@ -0,0 +1,96 @@
+package filter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ReportData summarizes a set of filters, useful to sanity check the result
+// of a generation.
+type ReportData struct {
+	// EntryCount is the number of filters.
+	EntryCount int
+	// LabelCount is the number of distinct labels applied or removed.
+	LabelCount int
+	// Senders are the distinct values matched by the 'from' criteria.
+	Senders []string
+	// ActionCounts maps the name of every action to the number of filters
+	// using it.
+	ActionCounts map[string]int
+}
+
+// Summary computes the report of the given filters.
+func Summary(fs Filters) ReportData {
+	res := ReportData{
+		EntryCount:   len(fs),
+		ActionCounts: map[string]int{},
+	}
+	labels := map[string]struct{}{}
+	senders := map[string]struct{}{}
+
+	for _, f := range fs {
+		for _, l := range []string{f.Action.AddLabel, f.Action.RemoveLabel} {
+			if l != "" {
+				labels[l] = struct{}{}
+			}
+		}
+		for _, s := range fieldTerms(f.Criteria.From) {
+			senders[s] = struct{}{}
+		}
+		for _, a := range actionNames(f.Action) {
+			res.ActionCounts[a]++
+		}
+	}
+
+	res.LabelCount = len(labels)
+	for s := range senders {
+		res.Senders = append(res.Senders, s)
+	}
+	sort.Strings(res.Senders)
+	return res
+}
+
+func (r ReportData) String() string {
+	var names []string
+	for name := range r.ActionCounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Filters: %d\n", r.EntryCount)
+	fmt.Fprintf(&b, "Labels: %d\n", r.LabelCount)
+	fmt.Fprintf(&b, "Senders: %d\n", len(r.Senders))
+	if len(r.Senders) > 0 {
+		fmt.Fprintf(&b, "  %s\n", strings.Join(r.Senders, ", "))
+	}
+	b.WriteString("Actions:\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %s: %d\n", name, r.ActionCounts[name])
+	}
+	return b.String()
+}
+
+// actionNames returns the names of the actions used, as printed by
+// Filter.String.
+func actionNames(a Actions) []string {
+	var res []string
+	add := func(name string, used bool) {
+		if used {
+			res = append(res, name)
+		}
+	}
+	add("archive", a.Archive)
+	add("delete", a.Delete)
+	add("mark as important", a.MarkImportant)
+	add("never mark as important", a.MarkNotImportant)
+	add("never mark as spam", a.MarkNotSpam)
+	add("mark as read", a.MarkRead)
+	add("star", a.Star)
+	add("categorize as", a.Category != "")
+	add("apply label", a.AddLabel != "")
+	add("remove label", a.RemoveLabel != "")
+	add("forward to", a.Forward != "")
+	return res
+}
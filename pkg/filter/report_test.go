@@ -0,0 +1,78 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mbrt/gmailctl/pkg/gmail"
+)
+
+func TestSummary(t *testing.T) {
+	fs := Filters{
+		{
+			Criteria: Criteria{From: "{a@x.com b@x.com}"},
+			Action:   Actions{Archive: true, AddLabel: "news"},
+		},
+		{
+			Criteria: Criteria{From: "{a@x.com b@x.com}"},
+			Action:   Actions{Archive: true, AddLabel: "reading"},
+		},
+		{
+			Criteria: Criteria{From: "spam@y.com"},
+			Action:   Actions{Delete: true},
+		},
+		{
+			Criteria: Criteria{Subject: "invoice"},
+			Action: Actions{
+				Category: gmail.CategoryUpdates,
+				AddLabel: "news",
+				Star:     true,
+			},
+		},
+	}
+	expected := ReportData{
+		EntryCount: 4,
+		LabelCount: 2,
+		Senders:    []string{"a@x.com", "b@x.com", "spam@y.com"},
+		ActionCounts: map[string]int{
+			"archive":       2,
+			"apply label":   3,
+			"delete":        1,
+			"categorize as": 1,
+			"star":          1,
+		},
+	}
+	got := Summary(fs)
+	assert.Equal(t, expected, got)
+
+	expectedStr := `Filters: 4
+Labels: 2
+Senders: 3
+  a@x.com, b@x.com, spam@y.com
+Actions:
+  apply label: 3
+  archive: 2
+  categorize as: 1
+  delete: 1
+  star: 1
+`
+	assert.Equal(t, expectedStr, got.String())
+}
+
+func TestSummaryEmpty(t *testing.T) {
+	got := Summary(nil)
+	assert.Equal(t, 0, got.EntryCount)
+	assert.Equal(t, "Filters: 0\nLabels: 0\nSenders: 0\nActions:\n", got.String())
+}
+
+func TestSummaryOrKeyword(t *testing.T) {
+	fs := Filters{
+		{
+			Criteria: Criteria{From: "(a@x.com OR b@x.com)"},
+			Action:   Actions{Archive: true},
+		},
+	}
+	// The keyword is not a sender.
+	assert.Equal(t, []string{"a@x.com", "b@x.com"}, Summary(fs).Senders)
+}
@@ -0,0 +1,40 @@
+package filter
+
+import "strings"
+
+// TagSelector selects rules by their tags.
+//
+// A rule is selected if it has at least one of the included tags, or if no
+// tags are included, and none of the excluded ones. Tags are compared case
+// insensitively.
+type TagSelector struct {
+	Include []string
+	Exclude []string
+}
+
+// Matches returns true if a rule with the given tags is selected.
+func (s TagSelector) Matches(tags []string) bool {
+	for _, t := range s.Exclude {
+		if containsFold(tags, t) {
+			return false
+		}
+	}
+	if len(s.Include) == 0 {
+		return true
+	}
+	for _, t := range s.Include {
+		if containsFold(tags, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(a []string, s string) bool {
+	for _, e := range a {
+		if strings.EqualFold(e, s) {
+			return true
+		}
+	}
+	return false
+}
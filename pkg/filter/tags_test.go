@@ -0,0 +1,67 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mbrt/gmailctl/pkg/parser"
+)
+
+func taggedRules() []parser.Rule {
+	rule := func(from string, tags ...string) parser.Rule {
+		return parser.Rule{
+			Tags: tags,
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{from},
+			},
+			Actions: parser.Actions{Archive: true},
+		}
+	}
+	return []parser.Rule{
+		rule("bank@x.com", "finance"),
+		rule("news@x.com", "newsletters"),
+		rule("invoices@x.com", "Finance", "newsletters"),
+		rule("boss@x.com"),
+	}
+}
+
+func generatedFrom(t *testing.T, opts GenerateOptions) []string {
+	t.Helper()
+	fs, err := FromRulesWithOptions(taggedRules(), opts)
+	assert.Nil(t, err)
+	var res []string
+	for _, f := range fs {
+		res = append(res, f.Criteria.From)
+	}
+	return res
+}
+
+func TestTagsInclude(t *testing.T) {
+	got := generatedFrom(t, GenerateOptions{
+		Tags: TagSelector{Include: []string{"finance"}},
+	})
+	assert.Equal(t, []string{"bank@x.com", "invoices@x.com"}, got)
+}
+
+func TestTagsExclude(t *testing.T) {
+	got := generatedFrom(t, GenerateOptions{
+		Tags: TagSelector{Exclude: []string{"newsletters"}},
+	})
+	assert.Equal(t, []string{"bank@x.com", "boss@x.com"}, got)
+
+	// Exclusions win over inclusions
+	got = generatedFrom(t, GenerateOptions{
+		Tags: TagSelector{
+			Include: []string{"finance"},
+			Exclude: []string{"newsletters"},
+		},
+	})
+	assert.Equal(t, []string{"bank@x.com"}, got)
+}
+
+func TestTagsNoSelector(t *testing.T) {
+	got := generatedFrom(t, GenerateOptions{})
+	assert.Len(t, got, 4)
+}
@@ -0,0 +1,183 @@
+package filter
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Term is a single term of a criteria field, e.g. 'a' or '"b c"' in
+// '{a "b c"}'.
+type Term struct {
+	Value string
+	// Quoted is true if the term was enclosed in quotes. Quoted terms are
+	// always literal, even if they look like keywords (e.g. "OR") or
+	// negations (e.g. "-a").
+	Quoted bool
+}
+
+// SplitTerms splits a list of space separated terms, taking into account
+// quoted ones. Escaped quotes are unescaped, while other escape sequences
+// are left untouched.
+//
+// An error is returned if the terms contain nested groups or unterminated
+// quotes.
+func SplitTerms(s string) ([]Term, error) {
+	var res []Term
+	var term strings.Builder
+	quoted, escaped, wasQuoted := false, false, false
+	flush := func() {
+		if term.Len() > 0 {
+			res = append(res, Term{Value: term.String(), Quoted: wasQuoted})
+			term.Reset()
+		}
+		wasQuoted = false
+	}
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			if r != '"' {
+				term.WriteRune('\\')
+			}
+			term.WriteRune(r)
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+			wasQuoted = true
+		case quoted:
+			term.WriteRune(r)
+		case r == ' ' || r == '\t':
+			flush()
+		case strings.ContainsRune("{}()", r):
+			return nil, errors.New("nested groups are not supported")
+		default:
+			term.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, errors.New("unterminated quote")
+	}
+	flush()
+	return res, nil
+}
+
+// SplitGroup splits a criteria field value into its terms, returning true if
+// they are grouped in an OR. Both the braces syntax (e.g. '{a b}') and the OR
+// keyword (e.g. '(a OR b)') are supported; unquoted OR keywords are dropped
+// from the result. Values without a group are returned as they are split by
+// SplitTerms.
+//
+// An error is returned if AND and OR are mixed in the same group, or for the
+// same reasons of SplitTerms.
+func SplitGroup(value string) ([]Term, bool, error) {
+	inner, or := value, false
+	if strings.HasPrefix(value, "{") && strings.HasSuffix(value, "}") {
+		inner, or = value[1:len(value)-1], true
+	} else if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		inner = value[1 : len(value)-1]
+	}
+	terms, err := SplitTerms(inner)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var res []Term
+	keywords := 0
+	for i, t := range terms {
+		if !isOrKeyword(t) {
+			res = append(res, t)
+			continue
+		}
+		// The keyword is valid only between two values.
+		if i == 0 || i == len(terms)-1 || isOrKeyword(terms[i-1]) {
+			return nil, false, errors.New("dangling OR")
+		}
+		keywords++
+	}
+	if keywords == 0 || or {
+		return res, or, nil
+	}
+	if keywords != len(res)-1 {
+		return nil, false, errors.New("mixing AND and OR is not supported")
+	}
+	return res, true, nil
+}
+
+func isOrKeyword(t Term) bool {
+	return !t.Quoted && t.Value == "OR"
+}
+
+// QueryTerms splits the AND groups at the top level of a query (e.g.
+// '(a b)', generated for an 'and' of 'has' values) into their terms, which
+// are returned first. The rest of the query is kept together in the last
+// term. For example '(a b) c -d' is split into 'a', 'b' and 'c -d'.
+//
+// Queries that can't be split are returned as they are.
+func QueryTerms(query string) []string {
+	parts, ok := splitTopLevel(query)
+	if !ok {
+		return []string{query}
+	}
+
+	var res, rest []string
+	for _, p := range parts {
+		if strings.HasPrefix(p, "(") && strings.HasSuffix(p, ")") {
+			inner, ok := splitTopLevel(p[1 : len(p)-1])
+			if ok && len(inner) > 1 && !containsString(inner, "OR") {
+				res = append(res, inner...)
+				continue
+			}
+		}
+		rest = append(rest, p)
+	}
+	if len(rest) > 0 {
+		res = append(res, strings.Join(rest, " "))
+	}
+	return res
+}
+
+// splitTopLevel splits the query on the spaces outside quotes and groups,
+// keeping the terms as they are. It returns false if quotes or groups are
+// not balanced.
+func splitTopLevel(query string) ([]string, bool) {
+	var res []string
+	var term strings.Builder
+	depth := 0
+	quoted, escaped := false, false
+
+	for _, r := range query {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '(' || r == '{':
+			depth++
+		case r == ')' || r == '}':
+			depth--
+			if depth < 0 {
+				return nil, false
+			}
+		case depth == 0 && (r == ' ' || r == '\t'):
+			if term.Len() > 0 {
+				res = append(res, term.String())
+				term.Reset()
+			}
+			continue
+		}
+		term.WriteRune(r)
+	}
+	if quoted || depth != 0 {
+		return nil, false
+	}
+	if term.Len() > 0 {
+		res = append(res, term.String())
+	}
+	return res, true
+}
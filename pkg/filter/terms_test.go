@@ -0,0 +1,88 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitTermsEscaped(t *testing.T) {
+	got, err := SplitTerms(`"He said \"hi\"" "status {ok}" a\b`)
+	assert.Nil(t, err)
+	expected := []Term{
+		{Value: `He said "hi"`, Quoted: true},
+		{Value: "status {ok}", Quoted: true},
+		{Value: `a\b`},
+	}
+	assert.Equal(t, expected, got)
+}
+
+func TestSplitTermsQuoted(t *testing.T) {
+	got, err := SplitTerms(`a OR "OR" -b "-c"`)
+	assert.Nil(t, err)
+	expected := []Term{
+		{Value: "a"},
+		{Value: "OR"},
+		{Value: "OR", Quoted: true},
+		{Value: "-b"},
+		{Value: "-c", Quoted: true},
+	}
+	assert.Equal(t, expected, got)
+}
+
+func TestSplitTermsInvalid(t *testing.T) {
+	_, err := SplitTerms("a (b c)")
+	assert.EqualError(t, err, "nested groups are not supported")
+	_, err = SplitTerms(`a "b`)
+	assert.EqualError(t, err, "unterminated quote")
+}
+
+func TestSplitGroup(t *testing.T) {
+	tests := []struct {
+		value  string
+		values []string
+		or     bool
+	}{
+		{"a", []string{"a"}, false},
+		{"{a b}", []string{"a", "b"}, true},
+		{"(a b)", []string{"a", "b"}, false},
+		{"(a OR b OR c)", []string{"a", "b", "c"}, true},
+		{`(a "OR" b)`, []string{"a", "OR", "b"}, false},
+	}
+	for _, tc := range tests {
+		terms, or, err := SplitGroup(tc.value)
+		assert.Nil(t, err)
+		var values []string
+		for _, t := range terms {
+			values = append(values, t.Value)
+		}
+		assert.Equal(t, tc.values, values, tc.value)
+		assert.Equal(t, tc.or, or, tc.value)
+	}
+
+	_, _, err := SplitGroup("(a OR b c)")
+	assert.EqualError(t, err, "mixing AND and OR is not supported")
+	_, _, err = SplitGroup("(a OR)")
+	assert.EqualError(t, err, "dangling OR")
+	_, _, err = SplitGroup("(a OR OR b)")
+	assert.EqualError(t, err, "dangling OR")
+}
+
+func TestQueryTerms(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"", nil},
+		{"list:a@x.com", []string{"list:a@x.com"}},
+		{`("phrase a" "phrase b") {foo bar}`, []string{`"phrase a"`, `"phrase b"`, "{foo bar}"}},
+		{"has words", []string{"has words"}},
+		{"(a b) c -(e f)", []string{"a", "b", "c -(e f)"}},
+		{"(a OR b) c", []string{"(a OR b) c"}},
+		{`("a (b" c)`, []string{`"a (b"`, "c"}},
+		{"(a b", []string{"(a b"}},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.expected, QueryTerms(tc.query), tc.query)
+	}
+}
@@ -0,0 +1,44 @@
+package filter
+
+import (
+	"github.com/hashicorp/go-multierror"
+
+	cfg "github.com/mbrt/gmailctl/pkg/config/v1alpha2"
+	"github.com/mbrt/gmailctl/pkg/parser"
+)
+
+// Validate checks the whole config and returns all the problems found, such
+// as rules without filters or actions, references to unknown named filters,
+// unknown categories and invalid labels.
+//
+// Differently from the generation of filters, it doesn't stop at the first
+// invalid rule.
+func Validate(config cfg.Config) error {
+	rules, reserr := parser.ParseRules(config)
+	if err := ValidateRules(rules); err != nil {
+		reserr = multierror.Append(reserr, err)
+	}
+	return reserr
+}
+
+// ValidateRules checks the rules returned by parser.ParseRules and returns
+// all the problems found while translating them into filters.
+//
+// Invalid and disabled rules, left empty by the parser, are skipped.
+func ValidateRules(rules []parser.Rule) error {
+	var reserr error
+	for i, rule := range rules {
+		if rule.Criteria == nil {
+			// Invalid rule, already reported
+			continue
+		}
+		if _, err := FromRule(rule); err != nil {
+			reserr = multierror.Append(reserr, RuleError{
+				Index:   i,
+				Summary: ruleSummary(rule),
+				Err:     err,
+			})
+		}
+	}
+	return reserr
+}
@@ -0,0 +1,66 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/assert"
+
+	cfg "github.com/mbrt/gmailctl/pkg/config/v1alpha2"
+)
+
+func TestValidate(t *testing.T) {
+	config := cfg.Config{
+		Filters: []cfg.NamedFilter{
+			{Name: "spam", Query: cfg.FilterNode{From: "spam@x.com"}},
+		},
+		Rules: []cfg.Rule{
+			{
+				// Valid
+				Filter:  cfg.FilterNode{RefName: "spam"},
+				Actions: cfg.Actions{Delete: true},
+			},
+			{
+				Filter:  cfg.FilterNode{From: "a@x.com"},
+				Actions: cfg.Actions{},
+			},
+			{
+				Filter:  cfg.FilterNode{},
+				Actions: cfg.Actions{Archive: true},
+			},
+			{
+				Filter:  cfg.FilterNode{RefName: "missing"},
+				Actions: cfg.Actions{Archive: true},
+			},
+			{
+				Filter:  cfg.FilterNode{From: "b@x.com"},
+				Actions: cfg.Actions{Category: "foo"},
+			},
+			{
+				Filter:  cfg.FilterNode{From: "c@x.com"},
+				Actions: cfg.Actions{Labels: []string{"a//b"}},
+			},
+		},
+	}
+
+	err := Validate(config)
+	merr, ok := err.(*multierror.Error)
+	assert.True(t, ok)
+	var msgs []string
+	for _, e := range merr.Errors {
+		msgs = append(msgs, e.Error())
+	}
+	expected := []string{
+		"invalid rule #1: rule has no effect: no actions would be applied",
+		"error parsing criteria for rule #2: empty filter node",
+		"error parsing criteria for rule #3: filter name 'missing' not found",
+		"error generating rule #4 (from: b@x.com): error generating actions: " +
+			"unrecognized category 'foo' (possible values: personal, social, updates, forums, promotions)",
+		"error generating rule #5 (from: c@x.com): error generating actions: " +
+			"invalid label 'a//b': empty name",
+	}
+	assert.Equal(t, expected, msgs)
+
+	config.Rules = config.Rules[:1]
+	assert.Nil(t, Validate(config))
+}
@@ -0,0 +1,82 @@
+package filter
+
+import (
+	"fmt"
+
+	"github.com/mbrt/gmailctl/pkg/parser"
+)
+
+// MaxFilters is the maximum number of filters allowed by Gmail.
+const MaxFilters = 1000
+
+// Warning is a non-fatal issue found in a set of filters.
+//
+// Filters with warnings can still be applied, but they might not behave as
+// expected.
+type Warning struct {
+	Message string
+	// RuleIndex is the position of the rule causing the warning. It's
+	// meaningful only if HasRule is set.
+	RuleIndex int
+	// HasRule is false if the warning is not related to a specific rule.
+	HasRule bool
+}
+
+// forRule returns a copy of the warning related to the given rule.
+func (w Warning) forRule(index int) Warning {
+	w.RuleIndex = index
+	w.HasRule = true
+	return w
+}
+
+func (w Warning) String() string {
+	if !w.HasRule {
+		return w.Message
+	}
+	return fmt.Sprintf("rule #%d: %s", w.RuleIndex, w.Message)
+}
+
+// CheckLimits returns a warning if the number of filters exceeds the given
+// limit (usually MaxFilters).
+func CheckLimits(fs Filters, limit int) []Warning {
+	if len(fs) <= limit {
+		return nil
+	}
+	return []Warning{
+		{
+			Message: fmt.Sprintf("%d filters exceed the limit of %d filters",
+				len(fs), limit),
+		},
+	}
+}
+
+// CheckActions returns warnings for suspicious combinations of actions,
+// which are allowed by Gmail but most likely not what the user meant.
+func CheckActions(a parser.Actions) []Warning {
+	if !a.Delete {
+		return nil
+	}
+
+	var res []Warning
+	pointless := func(action string) {
+		res = append(res, Warning{
+			Message: fmt.Sprintf("'%s' has no effect on deleted messages", action),
+		})
+	}
+	if len(a.Labels) > 0 {
+		pointless("labels")
+	}
+	if a.Category != "" {
+		pointless("category")
+	}
+	if a.Archive {
+		pointless("archive")
+	}
+	if a.Star {
+		pointless("star")
+	}
+	if a.MarkImportant != nil && *a.MarkImportant {
+		pointless("markImportant")
+	}
+	return res
+}
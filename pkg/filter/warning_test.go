@@ -0,0 +1,96 @@
+package filter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mbrt/gmailctl/pkg/parser"
+)
+
+func manyFilters(n int) Filters {
+	res := make(Filters, n)
+	for i := range res {
+		res[i] = Filter{
+			Criteria: Criteria{From: fmt.Sprintf("a%d@x.com", i)},
+			Action:   Actions{Archive: true},
+		}
+	}
+	return res
+}
+
+func TestCheckLimits(t *testing.T) {
+	assert.Empty(t, CheckLimits(manyFilters(MaxFilters), MaxFilters))
+
+	ws := CheckLimits(manyFilters(MaxFilters+1), MaxFilters)
+	expected := []Warning{
+		{Message: "1001 filters exceed the limit of 1000 filters"},
+	}
+	assert.Equal(t, expected, ws)
+}
+
+func TestCheckActions(t *testing.T) {
+	actions := parser.Actions{
+		Delete: true,
+		Labels: []string{"label1"},
+	}
+	expected := []Warning{
+		{Message: "'labels' has no effect on deleted messages"},
+	}
+	assert.Equal(t, expected, CheckActions(actions))
+
+	// The filters are generated anyway.
+	fs, err := FromRule(parser.Rule{
+		Criteria: &parser.Leaf{
+			Function: parser.FunctionFrom,
+			Args:     []string{"a@x.com"},
+		},
+		Actions: actions,
+	})
+	assert.Nil(t, err)
+	assert.Len(t, fs, 1)
+
+	assert.Empty(t, CheckActions(parser.Actions{Labels: []string{"a"}, Archive: true}))
+	assert.Empty(t, CheckActions(parser.Actions{Delete: true, Forward: "a@x.com"}))
+}
+
+func TestFromRulesWithWarnings(t *testing.T) {
+	rules := []parser.Rule{
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"a@x.com"},
+			},
+			Actions: parser.Actions{Star: true},
+		},
+		{
+			Criteria: &parser.Leaf{
+				Function: parser.FunctionFrom,
+				Args:     []string{"b@x.com"},
+			},
+			Actions: parser.Actions{
+				Delete: true,
+				Labels: []string{"junk"},
+			},
+		},
+	}
+
+	fs, ws, err := FromRulesWithWarnings(rules, GenerateOptions{StripDestructive: true})
+	assert.Nil(t, err)
+	assert.Len(t, fs, 2)
+	// The labels are not pointless when 'delete' is stripped.
+	expected := []Warning{
+		{Message: "'delete' is ignored because destructive actions are stripped", RuleIndex: 1, HasRule: true},
+	}
+	assert.Equal(t, expected, ws)
+	assert.Equal(t, "rule #1: 'delete' is ignored because destructive actions are stripped", ws[0].String())
+
+	// Without stripping, the field is not ignored.
+	_, ws, err = FromRulesWithWarnings(rules, GenerateOptions{})
+	assert.Nil(t, err)
+	expected = []Warning{
+		{Message: "'labels' has no effect on deleted messages", RuleIndex: 1, HasRule: true},
+	}
+	assert.Equal(t, expected, ws)
+}
@@ -0,0 +1,13 @@
+package gmail
+
+import "regexp"
+
+// domainRegexp matches domain names, which need at least a dot to be
+// distinguished from generic words.
+var domainRegexp = regexp.MustCompile(`^[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)+$`)
+
+// IsDomain returns true if the given string is a domain name, e.g.
+// 'example.com'.
+func IsDomain(s string) bool {
+	return domainRegexp.MatchString(s)
+}
@@ -1,5 +1,7 @@
 package gmail
 
+import "strings"
+
 // Categories supported by Gmail.
 const (
 	CategoryPersonal   Category = "personal"
@@ -24,3 +26,30 @@ func PossibleCategoryValues() []string {
 		string(CategoryPromotions),
 	}
 }
+
+// categoryAliases maps alternative names of the categories to the
+// canonical ones.
+var categoryAliases = map[string]Category{
+	"primary":       CategoryPersonal,
+	"notification":  CategoryUpdates,
+	"notifications": CategoryUpdates,
+	"update":        CategoryUpdates,
+	"forum":         CategoryForums,
+	"group":         CategoryForums,
+	"groups":        CategoryForums,
+	"promo":         CategoryPromotions,
+	"promotion":     CategoryPromotions,
+}
+
+// NormalizeCategory returns the canonical form of the given category,
+// ignoring the case and resolving common aliases (e.g. 'Notifications' is
+// equivalent to 'updates').
+//
+// Unknown categories are only lowercased.
+func NormalizeCategory(c Category) Category {
+	s := strings.ToLower(strings.TrimSpace(string(c)))
+	if alias, ok := categoryAliases[s]; ok {
+		return alias
+	}
+	return Category(s)
+}
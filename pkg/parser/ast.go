@@ -23,6 +23,8 @@ func (t OperationType) String() string {
 		return "and"
 	case OperationOr:
 		return "or"
+	case OperationNot:
+		return "not"
 	default:
 		return "<unknown>"
 	}
@@ -34,8 +36,19 @@ const (
 	FunctionFrom
 	FunctionTo
 	FunctionCc
+	FunctionBcc
 	FunctionSubject
 	FunctionList
+	FunctionDeliveredTo
+	FunctionLarger
+	FunctionSmaller
+	FunctionFilename
+	FunctionIs
+	FunctionIn
+	FunctionOlderThan
+	FunctionNewerThan
+	FunctionLabel
+	FunctionMessageID
 	FunctionHas
 	FunctionQuery
 )
@@ -53,10 +66,32 @@ func (f FunctionType) String() string {
 		return "to"
 	case FunctionCc:
 		return "cc"
+	case FunctionBcc:
+		return "bcc"
 	case FunctionSubject:
 		return "subject"
 	case FunctionList:
 		return "list"
+	case FunctionDeliveredTo:
+		return "deliveredto"
+	case FunctionLarger:
+		return "larger"
+	case FunctionSmaller:
+		return "smaller"
+	case FunctionFilename:
+		return "filename"
+	case FunctionIs:
+		return "is"
+	case FunctionIn:
+		return "in"
+	case FunctionOlderThan:
+		return "older_than"
+	case FunctionNewerThan:
+		return "newer_than"
+	case FunctionLabel:
+		return "label"
+	case FunctionMessageID:
+		return "rfc822msgid"
 	case FunctionHas:
 		return "has"
 	case FunctionQuery:
@@ -226,12 +261,16 @@ func functionsGrouping(tree CriteriaAST) int {
 		functions[leaf.Function] = append(functions[leaf.Function], leaf.Args...)
 	}
 
-	// Re-construct the grouped children
+	// Re-construct the grouped children, getting rid of duplicated
+	// arguments.
+	//
+	// Example:
+	// or(foo:x foo:x) => or(foo:x)
 	for ft, args := range functions {
 		newChildren = append(newChildren, &Leaf{
 			Function: ft,
 			Grouping: root.Operation,
-			Args:     args,
+			Args:     uniqueStrings(args),
 		})
 		count++
 	}
@@ -241,6 +280,21 @@ func functionsGrouping(tree CriteriaAST) int {
 	return count
 }
 
+// uniqueStrings returns the given strings without duplicates, preserving
+// the order of their first occurrence.
+func uniqueStrings(a []string) []string {
+	seen := map[string]struct{}{}
+	res := []string{}
+	for _, s := range a {
+		if _, ok := seen[s]; ok {
+			continue
+		}
+		seen[s] = struct{}{}
+		res = append(res, s)
+	}
+	return res
+}
+
 func removeRedundancy(tree CriteriaAST) (CriteriaAST, int) {
 	root, ok := tree.(*Node)
 	if !ok {
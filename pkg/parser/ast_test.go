@@ -40,3 +40,29 @@ func TestSimplify(t *testing.T) {
 	assert.Equal(t, expected, got)
 
 }
+
+func TestSimplifyDuplicates(t *testing.T) {
+	expr := and(
+		fn1(FunctionFrom, "a"),
+		or(
+			fn1(FunctionTo, "b"),
+			fn1(FunctionTo, "c"),
+			fn1(FunctionTo, "b"),
+		),
+		fn1(FunctionFrom, "a"),
+	)
+
+	expected := and(
+		fn(FunctionFrom, OperationAnd, "a"),
+		fn(FunctionTo, OperationOr, "b", "c"),
+	)
+	got, err := SimplifyCriteria(expr)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+}
+
+func TestOperationString(t *testing.T) {
+	assert.Equal(t, "and", OperationAnd.String())
+	assert.Equal(t, "or", OperationOr.String())
+	assert.Equal(t, "not", OperationNot.String())
+}
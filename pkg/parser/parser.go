@@ -3,6 +3,7 @@ package parser
 import (
 	"strings"
 
+	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 
 	cfg "github.com/mbrt/gmailctl/pkg/config/v1alpha2"
@@ -10,6 +11,8 @@ import (
 
 // Rule is an intermediate representation of a Gmail filter.
 type Rule struct {
+	Name     string
+	Tags     []string
 	Criteria CriteriaAST
 	Actions  Actions
 }
@@ -17,10 +20,24 @@ type Rule struct {
 // Actions contains the actions to be applied to a set of emails.
 type Actions cfg.Actions
 
+// Empty returns true if no actions are specified.
+func (a Actions) Empty() bool {
+	return cfg.Actions(a).Empty()
+}
+
+// ValidateRule returns an error if the rule would have no effect.
+func ValidateRule(rule Rule) error {
+	if rule.Actions.Empty() {
+		return errors.New("rule has no effect: no actions would be applied")
+	}
+	return nil
+}
+
 // Parse parses config file rules into their intermediate representation.
 //
 // Note that the number of rules and their contents might be different than the
-// original, because symplifications will be performed on the data.
+// original, because symplifications will be performed on the data. Disabled
+// rules are skipped.
 func Parse(config cfg.Config) ([]Rule, error) {
 	cmap, err := parseNamedFilters(config.Filters)
 	if err != nil {
@@ -29,23 +46,72 @@ func Parse(config cfg.Config) ([]Rule, error) {
 
 	res := []Rule{}
 	for i, rule := range config.Rules {
-		crit, err := parseCriteria(rule.Filter, cmap)
+		if !rule.IsEnabled() {
+			continue
+		}
+		prule, err := parseRule(i, rule, cmap)
 		if err != nil {
-			return nil, errors.Wrapf(err, "error parsing criteria for rule #%d", i)
+			return nil, err
 		}
+		res = append(res, prule)
+	}
+
+	return res, nil
+}
 
-		scrit, err := SimplifyCriteria(crit)
+// ParseRules parses every rule of the config independently.
+//
+// Differently from Parse, it doesn't stop at the first invalid rule, but
+// returns the errors of all of them. Every rule in the result is at the same
+// position of the original one, while the invalid and disabled ones are left
+// empty.
+func ParseRules(config cfg.Config) ([]Rule, error) {
+	cmap, err := parseNamedFilters(config.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	var reserr error
+	res := make([]Rule, len(config.Rules))
+	for i, rule := range config.Rules {
+		if !rule.IsEnabled() {
+			continue
+		}
+		prule, err := parseRule(i, rule, cmap)
 		if err != nil {
-			return nil, errors.Wrapf(err, "error simplifying criteria for rule #%d", i)
+			reserr = multierror.Append(reserr, err)
+			continue
 		}
+		res[i] = prule
+	}
+
+	return res, reserr
+}
 
-		res = append(res, Rule{
-			Criteria: scrit,
-			Actions:  Actions(rule.Actions),
-		})
+func parseRule(i int, rule cfg.Rule, cmap namedCriteriaMap) (Rule, error) {
+	if _, err := rule.Expiry(); err != nil {
+		return Rule{}, errors.Wrapf(err, "invalid rule #%d", i)
+	}
+	crit, err := parseCriteria(rule.Filter, cmap)
+	if err != nil {
+		return Rule{}, errors.Wrapf(err, "error parsing criteria for rule #%d", i)
 	}
 
-	return res, nil
+	scrit, err := SimplifyCriteria(crit)
+	if err != nil {
+		return Rule{}, errors.Wrapf(err, "error simplifying criteria for rule #%d", i)
+	}
+
+	prule := Rule{
+		Name:     rule.Name,
+		Tags:     rule.Tags,
+		Criteria: scrit,
+		Actions:  Actions(rule.Actions),
+	}
+	if err := ValidateRule(prule); err != nil {
+		return Rule{}, errors.Wrapf(err, "invalid rule #%d", i)
+	}
+	return prule, nil
 }
 
 // namedCriteriaMap maps a named filter to its parsed representation.
@@ -76,6 +142,10 @@ func parseCriteria(f cfg.FilterNode, nmap namedCriteriaMap) (CriteriaAST, error)
 		}, nil
 	}
 	if fn, arg := parseFunction(f); fn != FunctionNone {
+		if strings.TrimSpace(arg) == "" {
+			// A blank value would produce a filter matching everything.
+			return nil, errors.Errorf("empty value for '%v'", fn)
+		}
 		return &Leaf{
 			Function: fn,
 			Grouping: OperationNone,
@@ -127,12 +197,48 @@ func parseFunction(f cfg.FilterNode) (FunctionType, string) {
 	if f.Cc != "" {
 		return FunctionCc, f.Cc
 	}
+	if f.Bcc != "" {
+		return FunctionBcc, f.Bcc
+	}
 	if f.Subject != "" {
 		return FunctionSubject, f.Subject
 	}
 	if f.List != "" {
 		return FunctionList, f.List
 	}
+	if f.DeliveredTo != "" {
+		return FunctionDeliveredTo, f.DeliveredTo
+	}
+	if f.Larger != "" {
+		return FunctionLarger, f.Larger
+	}
+	if f.Smaller != "" {
+		return FunctionSmaller, f.Smaller
+	}
+	if f.Filename != "" {
+		return FunctionFilename, f.Filename
+	}
+	if f.Is != "" {
+		return FunctionIs, f.Is
+	}
+	if f.In != "" {
+		return FunctionIn, f.In
+	}
+	if f.OlderThan != "" {
+		return FunctionOlderThan, f.OlderThan
+	}
+	if f.NewerThan != "" {
+		return FunctionNewerThan, f.NewerThan
+	}
+	if f.Label != "" {
+		return FunctionLabel, f.Label
+	}
+	if f.MessageID != "" {
+		return FunctionMessageID, f.MessageID
+	}
+	if f.HasAttachment {
+		return FunctionQuery, "has:attachment"
+	}
 	if f.Has != "" {
 		return FunctionHas, f.Has
 	}
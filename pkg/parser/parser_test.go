@@ -82,3 +82,115 @@ func TestParse(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, expected, got)
 }
+
+func TestNoActions(t *testing.T) {
+	conf := cfg.Config{
+		Version: cfg.Version,
+		Rules: []cfg.Rule{
+			{
+				Filter:  cfg.FilterNode{From: "a"},
+				Actions: cfg.Actions{Archive: true},
+			},
+			{
+				Filter:  cfg.FilterNode{From: "b"},
+				Actions: cfg.Actions{Archive: false, Labels: []string{}},
+			},
+		},
+	}
+	_, err := Parse(conf)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "rule #1")
+	assert.Contains(t, err.Error(), "no actions would be applied")
+}
+
+func TestDisabledRules(t *testing.T) {
+	conf := cfg.Config{
+		Version: cfg.Version,
+		Rules: []cfg.Rule{
+			{
+				Enabled: boolptr(true),
+				Filter:  cfg.FilterNode{From: "a"},
+				Actions: cfg.Actions{Archive: true},
+			},
+			{
+				// Disabled rules are not validated
+				Enabled: boolptr(false),
+				Filter:  cfg.FilterNode{},
+				Actions: cfg.Actions{},
+			},
+			{
+				Filter:  cfg.FilterNode{From: "c"},
+				Actions: cfg.Actions{Star: true},
+			},
+		},
+	}
+	expected := []Rule{
+		{
+			Criteria: fn1(FunctionFrom, "a"),
+			Actions:  Actions{Archive: true},
+		},
+		{
+			Criteria: fn1(FunctionFrom, "c"),
+			Actions:  Actions{Star: true},
+		},
+	}
+	got, err := Parse(conf)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+
+	enabled, disabled := conf.WithoutDisabled()
+	assert.Equal(t, 1, disabled)
+	assert.Len(t, enabled.Rules, 2)
+}
+
+func TestInvalidExpiry(t *testing.T) {
+	conf := cfg.Config{
+		Version: cfg.Version,
+		Rules: []cfg.Rule{
+			{
+				ExpiresAt: "tomorrow",
+				Filter:    cfg.FilterNode{From: "a"},
+				Actions:   cfg.Actions{Archive: true},
+			},
+		},
+	}
+	_, err := Parse(conf)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "invalid expiry date")
+}
+
+func TestValidateRule(t *testing.T) {
+	rule := Rule{
+		Criteria: fn1(FunctionFrom, "a"),
+		Actions:  Actions{MarkSpam: boolptr(false)},
+	}
+	assert.Nil(t, ValidateRule(rule))
+
+	rule.Actions = Actions{}
+	assert.NotNil(t, ValidateRule(rule))
+}
+
+func TestBlankValues(t *testing.T) {
+	conf := cfg.Config{
+		Version: cfg.Version,
+		Rules: []cfg.Rule{
+			{
+				Filter:  cfg.FilterNode{From: "a"},
+				Actions: cfg.Actions{Archive: true},
+			},
+			{
+				Filter: cfg.FilterNode{
+					Or: []cfg.FilterNode{
+						{From: "b"},
+						{From: " \t"},
+					},
+				},
+				Actions: cfg.Actions{Archive: true},
+			},
+		},
+	}
+	_, err := Parse(conf)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "rule #1")
+	assert.Contains(t, err.Error(), "empty value for 'from'")
+}